@@ -0,0 +1,101 @@
+// Команда export выгружает датасет для дообучения и оценки моделей из истории задач
+// в JSONL формате chat completion:
+//
+//	go run ./app/cmd/export -target terraform -from 2025-01-01 -min-rating 4 -o dataset.jsonl
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"orchestrator/app/usecase"
+	"orchestrator/internal/domain/entity"
+	mongorepo "orchestrator/internal/infrastructure/store/mongodb"
+)
+
+func main() {
+	var (
+		filter   entity.DatasetFilter
+		from, to string
+		out      string
+	)
+	flag.StringVar(&filter.Target, "target", "", "only jobs of this target (terraform, kubernetes, ansible)")
+	flag.StringVar(&from, "from", "", "only jobs created at or after this date (YYYY-MM-DD or RFC 3339)")
+	flag.StringVar(&to, "to", "", "only jobs created before this date (YYYY-MM-DD or RFC 3339)")
+	flag.IntVar(&filter.MinRating, "min-rating", 0, "only jobs rated at least this (1-5); 0 includes unrated jobs")
+	flag.BoolVar(&filter.IncludeValidated, "validated", false, "include validated jobs that were not deployed yet")
+	flag.BoolVar(&filter.EditedOnly, "edited", false, "only jobs whose files were edited by a human")
+	flag.BoolVar(&filter.WithMetadata, "metadata", false, "add a metadata field to every record (for evaluation sets)")
+	flag.StringVar(&out, "o", "", "output file (default stdout)")
+	flag.Parse()
+
+	var err error
+	if filter.From, err = parseTime(from); err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	if filter.To, err = parseTime(to); err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(getEnv("MONGO_URI", "mongodb://localhost:27017")))
+	if err != nil {
+		log.Fatalf("mongo connect: %v", err)
+	}
+	defer func() {
+		_ = mongoClient.Disconnect(context.Background())
+	}()
+	db := mongoClient.Database(getEnv("MONGO_DB", "orchestrator"))
+
+	datasetSvc := usecase.NewDatasetService(
+		mongorepo.NewMongoJobRepo(db),
+		mongorepo.NewMongoConfigRepo(db),
+		usecase.NewPromptService(mongorepo.NewMongoPromptRepo(db), nil),
+	)
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			log.Fatalf("create %s: %v", out, err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Printf("close file err: %s", err)
+			}
+		}()
+		w = f
+	}
+
+	n, err := datasetSvc.Export(ctx, filter, w)
+	if err != nil {
+		log.Fatalf("export dataset: %v", err)
+	}
+	log.Printf("exported %d records", n)
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 
 	"orchestrator/app/config"
 	"orchestrator/app/usecase"
+	"orchestrator/internal/domain/repository"
 	"orchestrator/internal/infrastructure/llm"
 	"orchestrator/internal/infrastructure/metrics"
 	"orchestrator/internal/infrastructure/store/filesystem"
@@ -61,25 +63,143 @@ func main() {
 		log.Printf("err init file repo: %s", err)
 		return
 	}
+	usageRepo := mongorepo.NewMongoUsageRepo(db)
+	promptRepo := mongorepo.NewMongoPromptRepo(db)
+	experimentRepo := mongorepo.NewMongoExperimentRepo(db)
+	exampleRepo := mongorepo.NewMongoExampleRepo(db)
+	validationRepo := mongorepo.NewMongoValidationReportRepo(db)
+
+	budgets, err := config.LoadBudgets(cfg.Budget.File)
+	if err != nil {
+		log.Fatalf("load budgets: %v", err)
+	}
+
 	// Usecases / services
-	jobSvc := usecase.NewJobService(jobRepo, configRepo, usecase.NewTerraformDeployer())
-	configFileSvc := usecase.NewConfigService(configRepo)
+	budgetSvc := usecase.NewBudgetService(
+		usageRepo,
+		budgets.Policy,
+		budgets.Default,
+		budgets.Owners,
+		cfg.LLM.PromptPricePer1K,
+		cfg.LLM.CompletionPricePer1K,
+	)
+	promptSvc := usecase.NewPromptService(promptRepo, cfg.Prompts.Defaults)
+	if err := promptSvc.Seed(mongoCtx); err != nil {
+		log.Fatalf("seed prompts: %v", err)
+	}
+	experimentSvc := usecase.NewExperimentService(experimentRepo, jobRepo, promptSvc)
+	var embedder repository.Embedder
+	if cfg.FewShot.EmbeddingsURL != "" {
+		embedder = llm.NewHTTPEmbedder(cfg.FewShot.EmbeddingsURL, cfg.FewShot.EmbeddingsModel, cfg.FewShot.EmbeddingsAPIKey)
+	}
+	exampleSvc := usecase.NewExampleService(exampleRepo, embedder, cfg.FewShot.K, cfg.FewShot.MinSimilarity, logger)
+	configFileSvc := usecase.NewConfigService(configRepo, validationRepo)
+
+	var moduleRepo repository.ModuleCatalogRepository
+	if cfg.Modules.Dir != "" {
+		moduleRepo = filesystem.NewModuleCatalogRepository(cfg.Modules.Dir, cfg.Modules.SourcePrefix, cfg.Modules.GitURL, cfg.Modules.GitRef)
+	}
+	moduleSvc := usecase.NewModuleCatalogService(moduleRepo, cfg.Modules.TopK, cfg.Modules.MinScore)
+	if n, err := moduleSvc.Reload(mongoCtx); err != nil {
+		// без каталога генерация работает как раньше — не падаем
+		logger.Error("load module catalog failed", "dir", cfg.Modules.Dir, "err", err)
+	} else if moduleRepo != nil {
+		logger.Info("module catalog loaded", "dir", cfg.Modules.Dir, "modules", n)
+	}
 
 	// LLM client
-	llmClient := llm.NewAmveraGenerator(
-		cfg.LLM.APIKey,
-		cfg.LLM.BaseURL,
-		cfg.LLM.Model,
-	)
+	var llmClient repository.LLMGenerator
+	switch cfg.LLM.Mode {
+	case "replay":
+		llmClient = llm.NewReplayGenerator(cfg.LLM.CassetteDir, cfg.LLM.Model)
+		logger.Info("llm replay mode", "cassettes", cfg.LLM.CassetteDir)
+	case "record":
+		llmClient = llm.NewRecordingGenerator(
+			llm.NewAmveraGenerator(cfg.LLM.APIKey, cfg.LLM.BaseURL, cfg.LLM.Model, cfg.LLM.MaxContinuations),
+			cfg.LLM.CassetteDir,
+			cfg.LLM.Model,
+		)
+		logger.Info("llm record mode", "cassettes", cfg.LLM.CassetteDir)
+	default:
+		llmClient = llm.NewAmveraGenerator(
+			cfg.LLM.APIKey,
+			cfg.LLM.BaseURL,
+			cfg.LLM.Model,
+			cfg.LLM.MaxContinuations,
+		)
+	}
+
+	switch cfg.LLM.Cache.Backend {
+	case "mongo":
+		llmClient = llm.NewCachingGenerator(llmClient, mongorepo.NewMongoLLMCacheRepo(db), cfg.LLM.Model, cfg.LLM.Cache.TTL, logger)
+	case "filesystem":
+		cacheRepo, err := filesystem.NewLLMCacheRepository(cfg.LLM.Cache.Dir)
+		if err != nil {
+			log.Fatalf("init llm cache: %v", err)
+		}
+		llmClient = llm.NewCachingGenerator(llmClient, cacheRepo, cfg.LLM.Model, cfg.LLM.Cache.TTL, logger)
+	case "", "off":
+	default:
+		log.Fatalf("unknown LLM_CACHE backend %q", cfg.LLM.Cache.Backend)
+	}
+
+	// редактирование — внешний слой, чтобы секреты не попадали ни в кэш, ни в кассеты
+	if cfg.LLM.Redaction.Enabled {
+		redaction, err := config.LoadRedaction(cfg.LLM.Redaction.File)
+		if err != nil {
+			log.Fatalf("load redaction patterns: %v", err)
+		}
+		patterns := make([]llm.RedactionPattern, 0, len(redaction.Patterns))
+		for _, p := range redaction.Patterns {
+			patterns = append(patterns, llm.RedactionPattern{Kind: p.Kind, Pattern: p.Pattern, Restore: p.Restore})
+		}
+		redactor, err := llm.NewRedactor(patterns, redaction.EntropyThreshold)
+		if err != nil {
+			log.Fatalf("init redactor: %v", err)
+		}
+		llmClient = llm.NewRedactingGenerator(llmClient, redactor, logger)
+	}
+
+	rules, err := config.LoadRules(cfg.Validation.RulesFile)
+	if err != nil {
+		log.Fatalf("load rules: %v", err)
+	}
+	ruleProfiles, err := validator.NewRuleProfiles(rules.Default, rules.Projects)
+	if err != nil {
+		log.Fatalf("invalid rules file %s: %v", cfg.Validation.RulesFile, err)
+	}
+	schemas, err := validator.LoadProviderSchemas(cfg.Validation.ProviderSchemaPath)
+	if err != nil {
+		log.Fatalf("load provider schemas: %v", err)
+	}
+	if schemas != nil {
+		logger.Info("provider schemas loaded", "providers", schemas.Providers())
+	}
+	staticVal := validator.NewTerraformAnalyzer(ruleProfiles, schemas)
+	policyEngine := validator.NewPolicyEngine(cfg.Validation.PolicyDir)
+	if sets, err := policyEngine.Reload(context.Background()); err != nil {
+		log.Fatalf("load policies: %v", err)
+	} else if len(sets) > 0 {
+		logger.Info("policies loaded", "dir", cfg.Validation.PolicyDir, "sets", len(sets))
+	}
+	checker := usecase.NewRevisionChecker(staticVal, policyEngine, logger)
+	jobSvc := usecase.NewJobService(jobRepo, configRepo, configFileRepo, usecase.NewTerraformDeployer(), budgetSvc, exampleSvc, validationRepo, checker)
 
 	configGenerator := usecase.NewConfigGeneratorService(
 		jobRepo,
 		configRepo,
 		configFileRepo,
 		llmClient,
-		*validator.NewTerraformAnalyzer(), // static validator
-		nil,                               // sandbox validator
-		nil,                               // security validator
+		budgetSvc,
+		promptSvc,
+		experimentSvc,
+		moduleSvc,
+		exampleSvc,
+		validationRepo,
+		checker, // static validator, spec and rego policies
+		nil,     // sandbox validator
+		nil,     // security validator
+		cfg.Generation.MaxCandidates,
 		logger,
 	)
 
@@ -93,6 +213,14 @@ func main() {
 	handler := transport.NewOrchestratorHandler(
 		jobSvc,
 		configFileSvc,
+		budgetSvc,
+		promptSvc,
+		experimentSvc,
+		moduleSvc,
+		exampleSvc,
+		usecase.NewDatasetService(jobRepo, configRepo, promptSvc),
+		staticVal,
+		policyEngine,
 		logger,
 	)
 
@@ -101,7 +229,7 @@ func main() {
 	handler.RegisterRoutes(r)
 	corsHandler := handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "DELETE", "OPTIONS"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
 		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
 	)(r)
 
@@ -170,6 +298,25 @@ func loadConfig() *config.Config {
 			Model:     getEnv("AMVERA_MODEL", "gpt-5"),
 			MaxTokens: 4000,
 			Timeout:   60 * time.Minute,
+
+			MaxContinuations: getEnvInt("LLM_MAX_CONTINUATIONS", 2),
+
+			PromptPricePer1K:     getEnvFloat("LLM_PROMPT_PRICE_PER_1K", 0),
+			CompletionPricePer1K: getEnvFloat("LLM_COMPLETION_PRICE_PER_1K", 0),
+
+			Mode:        getEnv("LLM_MODE", "live"),
+			CassetteDir: getEnv("LLM_CASSETTE_DIR", "./cassettes"),
+
+			Cache: config.LLMCacheConfig{
+				Backend: getEnv("LLM_CACHE", "off"),
+				TTL:     getEnvDuration("LLM_CACHE_TTL", 7*24*time.Hour),
+				Dir:     getEnv("LLM_CACHE_DIR", "./llm_cache"),
+			},
+
+			Redaction: config.LLMRedactionConfig{
+				Enabled: getEnv("LLM_REDACT", "on") != "off",
+				File:    getEnv("LLM_REDACT_FILE", ""),
+			},
 		},
 		Mongo: config.MongoConfig{
 			URI:      getEnv("MONGO_URI", "mongodb://localhost:27017"),
@@ -178,10 +325,48 @@ func loadConfig() *config.Config {
 		FileRepo: config.FileRepoConfig{
 			ConfigDir: getEnv("CONFIG_DIR", "./deployments"),
 		},
+		Budget: config.BudgetConfig{
+			File: getEnv("BUDGETS_FILE", ""),
+		},
+		Generation: config.GenerationConfig{
+			MaxCandidates: getEnvInt("MAX_CANDIDATES", 3),
+		},
+		Modules: config.ModuleCatalogConfig{
+			Dir:          getEnv("MODULE_CATALOG_DIR", ""),
+			GitURL:       getEnv("MODULE_CATALOG_GIT_URL", ""),
+			GitRef:       getEnv("MODULE_CATALOG_GIT_REF", ""),
+			SourcePrefix: getEnv("MODULE_SOURCE_PREFIX", "internal"),
+			TopK:         getEnvInt("MODULE_TOP_K", 3),
+			MinScore:     getEnvFloat("MODULE_MIN_SCORE", 1),
+		},
+		FewShot: config.FewShotConfig{
+			K:                getEnvInt("FEW_SHOT_K", 2),
+			MinSimilarity:    getEnvFloat("FEW_SHOT_MIN_SIMILARITY", 0.3),
+			EmbeddingsURL:    getEnv("EMBEDDINGS_URL", ""),
+			EmbeddingsModel:  getEnv("EMBEDDINGS_MODEL", "nomic-embed-text"),
+			EmbeddingsAPIKey: getEnv("EMBEDDINGS_API_KEY", ""),
+		},
+		Validation: config.ValidationConfig{
+			RulesFile:          getEnv("RULES_FILE", ""),
+			ProviderSchemaPath: getEnv("PROVIDER_SCHEMA_PATH", ""),
+			PolicyDir:          getEnv("POLICY_DIR", ""),
+		},
 	}
 
-	if cfg.LLM.APIKey == "" {
-		log.Fatal("AMVERA_API_KEY env variable is required")
+	promptDefaults, err := config.ParsePromptDefaults(getEnv("PROMPT_DEFAULTS", "terraform=terraform@3"))
+	if err != nil {
+		log.Fatalf("invalid PROMPT_DEFAULTS: %v", err)
+	}
+	cfg.Prompts.Defaults = promptDefaults
+
+	switch cfg.LLM.Mode {
+	case "live", "record":
+		if cfg.LLM.APIKey == "" {
+			log.Fatal("AMVERA_API_KEY env variable is required (or set LLM_MODE=replay)")
+		}
+	case "replay":
+	default:
+		log.Fatalf("unknown LLM_MODE %q", cfg.LLM.Mode)
 	}
 
 	return cfg
@@ -193,3 +378,39 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return f
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return d
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// Budgets — содержимое файла бюджетов.
+//
+//	policy: reject # или queue
+//	default:
+//	  daily_tokens: 200000
+//	  monthly_tokens: 3000000
+//	owners:
+//	  platform-team:
+//	    monthly_cost: 150
+type Budgets struct {
+	Policy  entity.BudgetPolicy      `yaml:"policy"`
+	Default entity.Budget            `yaml:"default"`
+	Owners  map[string]entity.Budget `yaml:"owners"`
+}
+
+// LoadBudgets читает файл бюджетов. Пустой путь означает отсутствие лимитов.
+func LoadBudgets(path string) (*Budgets, error) {
+	budgets := &Budgets{Policy: entity.BudgetPolicyReject}
+	if path == "" {
+		return budgets, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read budgets file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, budgets); err != nil {
+		return nil, fmt.Errorf("parse budgets file %s: %w", path, err)
+	}
+
+	switch budgets.Policy {
+	case entity.BudgetPolicyReject, entity.BudgetPolicyQueue:
+	case "":
+		budgets.Policy = entity.BudgetPolicyReject
+	default:
+		return nil, fmt.Errorf("unknown budget policy %q", budgets.Policy)
+	}
+
+	return budgets, nil
+}
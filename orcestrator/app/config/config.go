@@ -1,12 +1,24 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+)
 
 type Config struct {
-	Server   HTTPServerConfig `json:"server"`
-	LLM      LLMConfig        `json:"llm"`
-	Mongo    MongoConfig
-	FileRepo FileRepoConfig
+	Server     HTTPServerConfig `json:"server"`
+	LLM        LLMConfig        `json:"llm"`
+	Mongo      MongoConfig
+	FileRepo   FileRepoConfig
+	Budget     BudgetConfig
+	Prompts    PromptsConfig
+	Generation GenerationConfig
+	Modules    ModuleCatalogConfig
+	FewShot    FewShotConfig
+	Validation ValidationConfig
 }
 
 type HTTPServerConfig struct {
@@ -22,6 +34,32 @@ type LLMConfig struct {
 	Model     string        `json:"model" default:"gpt-5"`
 	MaxTokens int           `json:"max_tokens" default:"4000"`
 	Timeout   time.Duration `json:"timeout" default:"60s"`
+	// MaxContinuations — максимум дозапросов продолжения для обрезанного ответа.
+	MaxContinuations int `json:"max_continuations" default:"2"`
+	// Цена за 1000 токенов, используется для учёта стоимости в бюджетах.
+	PromptPricePer1K     float64 `json:"prompt_price_per_1k"`
+	CompletionPricePer1K float64 `json:"completion_price_per_1k"`
+
+	// Mode — live (по умолчанию), record (live + запись кассет) или replay (только кассеты, без сети).
+	Mode        string `json:"mode" default:"live"`
+	CassetteDir string `json:"cassette_dir" default:"./cassettes"`
+
+	Cache LLMCacheConfig `json:"cache"`
+
+	Redaction LLMRedactionConfig `json:"redaction"`
+}
+
+type LLMRedactionConfig struct {
+	// Enabled — скрывать секреты и чувствительные значения перед запросом к модели.
+	Enabled bool   `json:"enabled" default:"true"`
+	File    string `json:"file"`
+}
+
+type LLMCacheConfig struct {
+	// Backend — off, mongo или filesystem.
+	Backend string        `json:"backend" default:"off"`
+	TTL     time.Duration `json:"ttl" default:"168h"`
+	Dir     string        `json:"dir" default:"./llm_cache"`
 }
 
 type MongoConfig struct {
@@ -32,3 +70,72 @@ type MongoConfig struct {
 type FileRepoConfig struct {
 	ConfigDir string `json:"config_dir" default:"./deployments"`
 }
+
+type BudgetConfig struct {
+	// File — путь к YAML файлу с бюджетами по владельцам/командам. Пустой путь отключает лимиты.
+	File string `json:"file"`
+}
+
+type GenerationConfig struct {
+	// MaxCandidates — верхняя граница числа кандидатов best-of-N на задачу.
+	MaxCandidates int `json:"max_candidates" default:"3"`
+}
+
+type ModuleCatalogConfig struct {
+	// Dir — локальный каталог модулей; пусто — каталог не используется.
+	Dir string `json:"dir"`
+	// GitURL и GitRef — репозиторий каталога, который клонируется в Dir.
+	GitURL string `json:"git_url"`
+	GitRef string `json:"git_ref"`
+	// SourcePrefix — префикс source модулей, например internal -> internal/vpc.
+	SourcePrefix string `json:"source_prefix" default:"internal"`
+	// TopK и MinScore — сколько модулей подставлять в промпт и минимальная релевантность.
+	TopK     int     `json:"top_k" default:"3"`
+	MinScore float64 `json:"min_score" default:"1"`
+}
+
+type FewShotConfig struct {
+	// K — сколько примеров подставлять в промпт; 0 отключает few-shot.
+	K             int     `json:"k" default:"2"`
+	MinSimilarity float64 `json:"min_similarity" default:"0.3"`
+	// EmbeddingsURL — OpenAI-совместимый endpoint эмбеддингов; пусто — лексический поиск.
+	EmbeddingsURL    string `json:"embeddings_url"`
+	EmbeddingsModel  string `json:"embeddings_model" default:"nomic-embed-text"`
+	EmbeddingsAPIKey string `json:"embeddings_api_key"`
+}
+
+type ValidationConfig struct {
+	// RulesFile — YAML с настройками правил статического анализа по проектам.
+	RulesFile string `json:"rules_file"`
+	// ProviderSchemaPath — вывод `terraform providers schema -json` (файл или каталог *.json)
+	// для проверки ресурсов по схемам провайдеров; пусто — без схем.
+	ProviderSchemaPath string `json:"provider_schema_path"`
+	// PolicyDir — каталог политик Rego: *.rego для всех проектов и projects/<project>/*.rego.
+	PolicyDir string `json:"policy_dir"`
+}
+
+type PromptsConfig struct {
+	// Defaults закрепляет промпт по умолчанию для каждого target, например terraform=terraform@3.
+	Defaults map[string]entity.PromptRef `json:"defaults"`
+}
+
+// ParsePromptDefaults разбирает строку вида "terraform=terraform@3,kubernetes=k8s".
+func ParsePromptDefaults(s string) (map[string]entity.PromptRef, error) {
+	defaults := make(map[string]entity.PromptRef)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		target, refStr, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(target) == "" {
+			return nil, fmt.Errorf("invalid prompt default %q, expected target=id@version", pair)
+		}
+		ref, err := entity.ParsePromptRef(refStr)
+		if err != nil {
+			return nil, err
+		}
+		defaults[strings.TrimSpace(target)] = ref
+	}
+	return defaults, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redaction — содержимое файла шаблонов чувствительных данных.
+//
+//	entropy_threshold: 4.0
+//	patterns:
+//	  - kind: internal_host
+//	    pattern: '[a-z0-9-]+\.corp\.example\.com'
+//	    restore: true
+//	  - kind: db_password
+//	    pattern: 'db_pass=(\S+)'
+type Redaction struct {
+	EntropyThreshold float64            `yaml:"entropy_threshold"`
+	Patterns         []RedactionPattern `yaml:"patterns"`
+}
+
+type RedactionPattern struct {
+	Kind    string `yaml:"kind"`
+	Pattern string `yaml:"pattern"`
+	// Restore — вернуть исходное значение в сгенерированные файлы; иначе подставляется REPLACE_ME.
+	Restore bool `yaml:"restore"`
+}
+
+// LoadRedaction читает файл шаблонов. Пустой путь означает только встроенные шаблоны.
+func LoadRedaction(path string) (*Redaction, error) {
+	redaction := &Redaction{EntropyThreshold: 4.0}
+	if path == "" {
+		return redaction, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read redaction file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, redaction); err != nil {
+		return nil, fmt.Errorf("parse redaction file %s: %w", path, err)
+	}
+	for i, p := range redaction.Patterns {
+		if p.Pattern == "" {
+			return nil, fmt.Errorf("redaction pattern %d (%s) is empty", i, p.Kind)
+		}
+	}
+
+	return redaction, nil
+}
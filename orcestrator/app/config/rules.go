@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules — настройки правил статического анализа: значение — error, warning, info или off.
+//
+//	default:
+//	  TF005: off     # lifecycle не обязателен
+//	projects:
+//	  payments:
+//	    TF007: error # захардкоженные секреты блокируют деплой
+type Rules struct {
+	Default  map[string]string            `yaml:"default"`
+	Projects map[string]map[string]string `yaml:"projects"`
+}
+
+// LoadRules читает файл настроек правил. Пустой путь означает серьёзность по умолчанию.
+func LoadRules(path string) (*Rules, error) {
+	rules := &Rules{}
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("parse rules file %s: %w", path, err)
+	}
+	return rules, nil
+}
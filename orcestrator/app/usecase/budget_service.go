@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+// ErrBudgetExceeded возвращается, когда у владельца исчерпан дневной или месячный бюджет LLM.
+var ErrBudgetExceeded = errors.New("llm budget exceeded")
+
+type BudgetUsecase interface {
+	Policy() entity.BudgetPolicy
+	// Check возвращает ErrBudgetExceeded, если владелец исчерпал бюджет в текущем окне.
+	Check(ctx context.Context, owner string) error
+	State(ctx context.Context, owner string) (*entity.BudgetState, error)
+	States(ctx context.Context) ([]*entity.BudgetState, error)
+	Record(ctx context.Context, owner, jobID, model string, usage entity.TokenUsage) error
+}
+
+var _ BudgetUsecase = (*BudgetService)(nil)
+
+type BudgetService struct {
+	usageRepo repository.UsageRepository
+
+	policy        entity.BudgetPolicy
+	defaultBudget entity.Budget
+	owners        map[string]entity.Budget
+
+	promptPricePer1K     float64
+	completionPricePer1K float64
+
+	now func() time.Time
+}
+
+func NewBudgetService(
+	ur repository.UsageRepository,
+	policy entity.BudgetPolicy,
+	defaultBudget entity.Budget,
+	owners map[string]entity.Budget,
+	promptPricePer1K, completionPricePer1K float64,
+) *BudgetService {
+	if owners == nil {
+		owners = map[string]entity.Budget{}
+	}
+	return &BudgetService{
+		usageRepo:            ur,
+		policy:               policy,
+		defaultBudget:        defaultBudget,
+		owners:               owners,
+		promptPricePer1K:     promptPricePer1K,
+		completionPricePer1K: completionPricePer1K,
+		now:                  func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *BudgetService) Policy() entity.BudgetPolicy {
+	return s.policy
+}
+
+func (s *BudgetService) Check(ctx context.Context, owner string) error {
+	state, err := s.State(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("budget state for %s: %w", owner, err)
+	}
+	if state.Exhausted {
+		return fmt.Errorf("%w for owner %s", ErrBudgetExceeded, state.Owner)
+	}
+	return nil
+}
+
+func (s *BudgetService) State(ctx context.Context, owner string) (*entity.BudgetState, error) {
+	owner = ownerOrDefault(owner)
+	budget := s.budgetFor(owner)
+
+	now := s.now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	daily, err := s.window(ctx, owner, dayStart, dayStart.AddDate(0, 0, 1), budget.DailyTokens, budget.DailyCost)
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := s.window(ctx, owner, monthStart, monthStart.AddDate(0, 1, 0), budget.MonthlyTokens, budget.MonthlyCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.BudgetState{
+		Owner:     owner,
+		Policy:    s.policy,
+		Daily:     daily,
+		Monthly:   monthly,
+		Exhausted: daily.Exhausted || monthly.Exhausted,
+	}, nil
+}
+
+func (s *BudgetService) States(ctx context.Context) ([]*entity.BudgetState, error) {
+	owners := []string{entity.DefaultOwner}
+	for owner := range s.owners {
+		if owner != entity.DefaultOwner {
+			owners = append(owners, owner)
+		}
+	}
+	sort.Strings(owners[1:])
+
+	states := make([]*entity.BudgetState, 0, len(owners))
+	for _, owner := range owners {
+		state, err := s.State(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (s *BudgetService) Record(ctx context.Context, owner, jobID, model string, usage entity.TokenUsage) error {
+	if usage.TotalTokens == 0 && usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		return nil
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	rec := &entity.UsageRecord{
+		Owner:     ownerOrDefault(owner),
+		JobID:     jobID,
+		Model:     model,
+		Usage:     usage,
+		Cost:      s.cost(usage),
+		CreatedAt: s.now(),
+	}
+	if err := s.usageRepo.Record(ctx, rec); err != nil {
+		return fmt.Errorf("record usage for job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+func (s *BudgetService) window(ctx context.Context, owner string, from, to time.Time, tokensLimit int64, costLimit float64) (entity.BudgetWindow, error) {
+	totals, err := s.usageRepo.Sum(ctx, owner, from, to)
+	if err != nil {
+		return entity.BudgetWindow{}, fmt.Errorf("sum usage: %w", err)
+	}
+	return entity.BudgetWindow{
+		Start:       from,
+		End:         to,
+		TokensUsed:  totals.Tokens,
+		TokensLimit: tokensLimit,
+		CostUsed:    totals.Cost,
+		CostLimit:   costLimit,
+		Exhausted: (tokensLimit > 0 && totals.Tokens >= tokensLimit) ||
+			(costLimit > 0 && totals.Cost >= costLimit),
+	}, nil
+}
+
+func (s *BudgetService) budgetFor(owner string) entity.Budget {
+	if b, ok := s.owners[owner]; ok {
+		return b
+	}
+	return s.defaultBudget
+}
+
+func (s *BudgetService) cost(usage entity.TokenUsage) float64 {
+	return float64(usage.PromptTokens)/1000*s.promptPricePer1K +
+		float64(usage.CompletionTokens)/1000*s.completionPricePer1K
+}
+
+func ownerOrDefault(owner string) string {
+	if owner == "" {
+		return entity.DefaultOwner
+	}
+	return owner
+}
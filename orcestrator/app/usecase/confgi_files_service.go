@@ -12,16 +12,18 @@ type ConfigFilesUseCase interface {
 	SaveFiles(ctx context.Context, files []*entity.ConfigFile, requestID string) error
 	GetFiles(ctx context.Context, requestID string) ([]*entity.ConfigFile, error)
 	GetFilesByJobID(ctx context.Context, jobID string) ([]*entity.ConfigFile, error)
+	GetFilesByRevision(ctx context.Context, jobID string, revision int) ([]*entity.ConfigFile, error)
 	ListRequests(ctx context.Context) ([]string, error)
 	DeleteRequest(ctx context.Context, requestID string) error
 }
 
 type ConfigService struct {
-	repo repository.ConfgiFileRepository
+	repo    repository.ConfgiFileRepository
+	reports repository.ValidationReportRepository
 }
 
-func NewConfigService(repo repository.ConfgiFileRepository) ConfigFilesUseCase {
-	return &ConfigService{repo: repo}
+func NewConfigService(repo repository.ConfgiFileRepository, reports repository.ValidationReportRepository) ConfigFilesUseCase {
+	return &ConfigService{repo: repo, reports: reports}
 }
 
 var _ ConfigFilesUseCase = (*ConfigService)(nil)
@@ -58,6 +60,23 @@ func (s *ConfigService) GetFilesByJobID(ctx context.Context, jobID string) ([]*e
 	if err != nil {
 		return nil, fmt.Errorf("get files for job %s: %w", jobID, err)
 	}
+	if err := s.attachFindings(ctx, jobID, files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (s *ConfigService) GetFilesByRevision(ctx context.Context, jobID string, revision int) ([]*entity.ConfigFile, error) {
+	if jobID == "" {
+		return nil, fmt.Errorf("jobID is required")
+	}
+	files, err := s.repo.GetFilesByRevision(ctx, jobID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("get files for job %s revision %d: %w", jobID, revision, err)
+	}
+	if err := s.attachFindings(ctx, jobID, files); err != nil {
+		return nil, err
+	}
 	return files, nil
 }
 
@@ -78,3 +97,30 @@ func (s *ConfigService) DeleteRequest(ctx context.Context, requestID string) err
 	}
 	return nil
 }
+
+// attachFindings дополняет файлы замечаниями из отчётов валидации их ревизий.
+func (s *ConfigService) attachFindings(ctx context.Context, jobID string, files []*entity.ConfigFile) error {
+	reports := map[int]*entity.ValidationReport{}
+	for _, file := range files {
+		report, ok := reports[file.Revision]
+		if !ok {
+			var err error
+			report, err = s.reports.Get(ctx, jobID, file.Revision)
+			if err != nil {
+				return fmt.Errorf("get validation report for job %s revision %d: %w", jobID, file.Revision, err)
+			}
+			reports[file.Revision] = report
+		}
+		if report == nil {
+			continue
+		}
+		file.Findings = report.FindingsFor(file.Name)
+		file.HasError = false
+		for _, f := range file.Findings {
+			if f.Severity == entity.SeverityError || f.Severity == "" {
+				file.HasError = true
+			}
+		}
+	}
+	return nil
+}
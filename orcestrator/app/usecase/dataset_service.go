@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+type DatasetUsecase interface {
+	// Export пишет в w JSONL датасет «описание → принятые файлы» и возвращает число записей.
+	Export(ctx context.Context, filter entity.DatasetFilter, w io.Writer) (int, error)
+}
+
+var _ DatasetUsecase = (*DatasetService)(nil)
+
+// DatasetService собирает датасеты для дообучения и оценки моделей из истории задач.
+type DatasetService struct {
+	jobsRepo   repository.JobRepository
+	configRepo repository.ConfgiFileRepository
+	prompts    PromptUsecase
+}
+
+func NewDatasetService(jr repository.JobRepository, cr repository.ConfgiFileRepository, prompts PromptUsecase) *DatasetService {
+	return &DatasetService{jobsRepo: jr, configRepo: cr, prompts: prompts}
+}
+
+func (s *DatasetService) Export(ctx context.Context, filter entity.DatasetFilter, w io.Writer) (int, error) {
+	deployed, err := s.jobsRepo.ListByStatus(ctx, entity.JobStatusDeployed)
+	if err != nil {
+		return 0, fmt.Errorf("list deployed jobs: %w", err)
+	}
+	// развёрнутая ревизия могла не пройти проверки (деплой не требует их успеха) — берём только проверенные
+	jobs := validatedJobs(deployed)
+	if filter.IncludeValidated {
+		ready, err := s.jobsRepo.ListByStatus(ctx, entity.JobStatusReady2Deploy)
+		if err != nil {
+			return 0, fmt.Errorf("list validated jobs: %w", err)
+		}
+		jobs = append(jobs, validatedJobs(ready)...)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+
+	prompts := map[string]string{} // "id@version" -> текст системного промпта
+	enc := json.NewEncoder(w)
+	n := 0
+	for _, job := range jobs {
+		if !matchesDataset(job, filter) {
+			continue
+		}
+		files, err := s.configRepo.GetFilesByRevision(ctx, job.ID, job.Revision)
+		if err != nil {
+			return n, fmt.Errorf("get files of job %s: %w", job.ID, err)
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		ref := entity.PromptRef{ID: job.PromptID, Version: job.PromptVersion}
+		system, ok := prompts[ref.String()]
+		if !ok && ref.ID != "" {
+			if p, err := s.prompts.Get(ctx, ref.ID, ref.Version); err == nil {
+				system = p.Text
+			}
+			prompts[ref.String()] = system
+		}
+
+		record := entity.DatasetRecord{}
+		if system != "" {
+			record.Messages = append(record.Messages, entity.DatasetMessage{Role: "system", Content: system})
+		}
+		record.Messages = append(record.Messages,
+			entity.DatasetMessage{Role: "user", Content: job.Description},
+			entity.DatasetMessage{Role: "assistant", Content: fencedFiles(files)},
+		)
+		if filter.WithMetadata {
+			record.Metadata = &entity.DatasetMetadata{
+				JobID:       job.ID,
+				Target:      job.Target,
+				Status:      job.Status,
+				Revision:    job.Revision,
+				Rating:      job.Rating,
+				HumanEdited: job.HumanEdited,
+				Prompt:      ref.String(),
+				Model:       job.Model,
+				CreatedAt:   job.CreatedAt,
+			}
+		}
+		if err := enc.Encode(record); err != nil {
+			return n, fmt.Errorf("write record: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+func matchesDataset(job *entity.Job, f entity.DatasetFilter) bool {
+	switch {
+	case f.Target != "" && job.Target != f.Target:
+		return false
+	case !f.From.IsZero() && job.CreatedAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && !job.CreatedAt.Before(f.To):
+		return false
+	case f.MinRating > 0 && job.Rating < f.MinRating:
+		return false
+	case f.EditedOnly && !job.HumanEdited:
+		return false
+	}
+	return true
+}
+
+// validatedJobs — задачи, текущая ревизия которых прошла статический анализ и песочницу (если она запускалась).
+func validatedJobs(jobs []*entity.Job) []*entity.Job {
+	var out []*entity.Job
+	for _, job := range jobs {
+		if job.StaticPassed != nil && *job.StaticPassed && (job.SandboxPassed == nil || *job.SandboxPassed) {
+			out = append(out, job)
+		}
+	}
+	return out
+}
+
+// fencedFiles — ответ ассистента в том же формате, которого промпт требует от модели.
+func fencedFiles(files []*entity.ConfigFile) string {
+	sorted := append([]*entity.ConfigFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var b strings.Builder
+	for i, f := range sorted {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "```%s\n%s", f.Name, f.Content)
+		if !strings.HasSuffix(f.Content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
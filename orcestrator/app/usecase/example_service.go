@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+var ErrExampleNotFound = errors.New("example not found")
+
+// maxExampleChars — примеры с файлами крупнее не подставляются автоматически (только закреплённые),
+// чтобы few-shot не вытеснял из контекста саму задачу.
+const maxExampleChars = 8000
+
+type ExampleUsecase interface {
+	// Capture сохраняет файлы успешной задачи как пример; закрепление и исключение сохраняются.
+	Capture(ctx context.Context, job *entity.Job, files []*entity.ConfigFile, source entity.ExampleSource) error
+	Get(ctx context.Context, id string) (*entity.Example, error)
+	List(ctx context.Context, target string) ([]*entity.Example, error)
+	Delete(ctx context.Context, id string) error
+	Pin(ctx context.Context, id string, pinned bool) (*entity.Example, error)
+	Exclude(ctx context.Context, id string, excluded bool) (*entity.Example, error)
+	// Search подбирает примеры для описания: сначала закреплённые, затем самые похожие.
+	// excludeID — пример, который нельзя выбирать (сама задача при повторной генерации).
+	Search(ctx context.Context, target, query, excludeID string, limit int) ([]entity.ExampleMatch, error)
+	// FewShot возвращает текст few-shot примеров для промпта задачи и ID подставленных примеров.
+	FewShot(ctx context.Context, job *entity.Job) (string, []string)
+}
+
+var _ ExampleUsecase = (*ExampleService)(nil)
+
+type ExampleService struct {
+	repo          repository.ExampleRepository
+	embedder      repository.Embedder // nil — лексический поиск
+	k             int
+	minSimilarity float64
+	logger        *slog.Logger
+}
+
+func NewExampleService(
+	repo repository.ExampleRepository,
+	embedder repository.Embedder,
+	k int,
+	minSimilarity float64,
+	logger *slog.Logger,
+) *ExampleService {
+	return &ExampleService{repo: repo, embedder: embedder, k: k, minSimilarity: minSimilarity, logger: logger}
+}
+
+func (s *ExampleService) Capture(ctx context.Context, job *entity.Job, files []*entity.ConfigFile, source entity.ExampleSource) error {
+	if len(files) == 0 {
+		return nil
+	}
+	example, err := s.repo.GetByID(ctx, job.ID)
+	if err != nil {
+		return fmt.Errorf("get example %s: %w", job.ID, err)
+	}
+	if example == nil {
+		example = &entity.Example{ID: job.ID}
+	}
+	// развёрнутая ревизия — более сильный сигнал, чем просто провалидированная
+	if !(example.Source == entity.ExampleSourceDeployed && example.Revision == job.Revision) {
+		example.Source = source
+	}
+	if example.Description != job.Description {
+		example.Embedding, example.EmbeddingModel = nil, ""
+	}
+	example.Target = job.Target
+	example.Description = job.Description
+	example.Revision = job.Revision
+	example.Files = make([]entity.ExampleFile, 0, len(files))
+	for _, f := range files {
+		example.Files = append(example.Files, entity.ExampleFile{Name: f.Name, Content: f.Content})
+	}
+	sort.Slice(example.Files, func(i, j int) bool { return example.Files[i].Name < example.Files[j].Name })
+
+	s.embedMissing(ctx, []*entity.Example{example})
+
+	if err := s.repo.Upsert(ctx, example); err != nil {
+		return fmt.Errorf("save example %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func (s *ExampleService) Get(ctx context.Context, id string) (*entity.Example, error) {
+	example, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get example %s: %w", id, err)
+	}
+	if example == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExampleNotFound, id)
+	}
+	return example, nil
+}
+
+func (s *ExampleService) List(ctx context.Context, target string) ([]*entity.Example, error) {
+	return s.repo.ListByTarget(ctx, target)
+}
+
+func (s *ExampleService) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete example %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *ExampleService) Pin(ctx context.Context, id string, pinned bool) (*entity.Example, error) {
+	return s.update(ctx, id, func(e *entity.Example) {
+		e.Pinned = pinned
+		if pinned {
+			e.Excluded = false
+		}
+	})
+}
+
+func (s *ExampleService) Exclude(ctx context.Context, id string, excluded bool) (*entity.Example, error) {
+	return s.update(ctx, id, func(e *entity.Example) {
+		e.Excluded = excluded
+		if excluded {
+			e.Pinned = false
+		}
+	})
+}
+
+func (s *ExampleService) update(ctx context.Context, id string, apply func(*entity.Example)) (*entity.Example, error) {
+	example, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	apply(example)
+	if err := s.repo.Upsert(ctx, example); err != nil {
+		return nil, fmt.Errorf("save example %s: %w", id, err)
+	}
+	return example, nil
+}
+
+func (s *ExampleService) Search(ctx context.Context, target, query, excludeID string, limit int) ([]entity.ExampleMatch, error) {
+	if limit <= 0 {
+		limit = s.k
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	all, err := s.repo.ListByTarget(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("list examples: %w", err)
+	}
+
+	var candidates []*entity.Example
+	for _, e := range all {
+		if e.Excluded || e.ID == excludeID {
+			continue
+		}
+		if !e.Pinned && exampleSize(e) > maxExampleChars {
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	similarity := s.similarity(ctx, query, candidates)
+	var matches []entity.ExampleMatch
+	for i, e := range candidates {
+		if !e.Pinned && similarity[i] < s.minSimilarity {
+			continue
+		}
+		matches = append(matches, entity.ExampleMatch{Example: e, Similarity: math.Round(similarity[i]*1000) / 1000})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Example.Pinned != matches[j].Example.Pinned {
+			return matches[i].Example.Pinned
+		}
+		return matches[i].Similarity > matches[j].Similarity
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (s *ExampleService) FewShot(ctx context.Context, job *entity.Job) (string, []string) {
+	matches, err := s.Search(ctx, job.Target, job.Description, job.ID, s.k)
+	if err != nil {
+		// без примеров генерация всё равно возможна
+		s.logger.Warn("few-shot example search failed", "job_id", job.ID, "err", err)
+		return "", nil
+	}
+	if len(matches) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Examples of accepted solutions for similar requests. Follow their conventions, ")
+	b.WriteString("but implement only what the new request asks for.\n")
+	ids := make([]string, 0, len(matches))
+	for i, m := range matches {
+		fmt.Fprintf(&b, "\nExample %d request: %s\nExample %d files:\n", i+1, m.Example.Description, i+1)
+		for _, f := range m.Example.Files {
+			fmt.Fprintf(&b, "```%s\n%s", f.Name, f.Content)
+			if !strings.HasSuffix(f.Content, "\n") {
+				b.WriteString("\n")
+			}
+			b.WriteString("```\n")
+		}
+		ids = append(ids, m.Example.ID)
+	}
+	return b.String(), ids
+}
+
+// similarity — косинусная близость описаний: по эмбеддингам, если endpoint доступен,
+// иначе по частотам слов.
+func (s *ExampleService) similarity(ctx context.Context, query string, examples []*entity.Example) []float64 {
+	scores := make([]float64, len(examples))
+
+	if s.embedder != nil {
+		s.embedMissing(ctx, examples)
+		vectors, err := s.embedder.Embed(ctx, []string{query})
+		if err == nil && len(vectors) == 1 {
+			for i, e := range examples {
+				if e.EmbeddingModel == s.embedder.Model() {
+					scores[i] = cosine(vectors[0], e.Embedding)
+				}
+			}
+			return scores
+		}
+		s.logger.Warn("embed query failed; lexical fallback", "err", err)
+	}
+
+	q := termVector(query)
+	for i, e := range examples {
+		scores[i] = termCosine(q, termVector(e.Description))
+	}
+	return scores
+}
+
+// embedMissing досчитывает эмбеддинги примеров без вектора текущей модели и сохраняет их.
+func (s *ExampleService) embedMissing(ctx context.Context, examples []*entity.Example) {
+	if s.embedder == nil {
+		return
+	}
+	var missing []*entity.Example
+	var texts []string
+	for _, e := range examples {
+		if e.EmbeddingModel != s.embedder.Model() || len(e.Embedding) == 0 {
+			missing = append(missing, e)
+			texts = append(texts, e.Description)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		s.logger.Warn("embed examples failed", "count", len(missing), "err", err)
+		return
+	}
+	for i, e := range missing {
+		e.Embedding, e.EmbeddingModel = vectors[i], s.embedder.Model()
+		// новый пример сохранит вызывающий; у существующих обновляем вектор сразу
+		if !e.CreatedAt.IsZero() {
+			if err := s.repo.Upsert(ctx, e); err != nil {
+				s.logger.Warn("save example embedding failed", "example_id", e.ID, "err", err)
+			}
+		}
+	}
+}
+
+func exampleSize(e *entity.Example) int {
+	n := len(e.Description)
+	for _, f := range e.Files {
+		n += len(f.Content)
+	}
+	return n
+}
+
+func termVector(text string) map[string]float64 {
+	v := map[string]float64{}
+	for _, t := range tokenize(text) {
+		v[t]++
+	}
+	return v
+}
+
+func termCosine(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for t, x := range a {
+		dot += x * b[t]
+		na += x * x
+	}
+	for _, y := range b {
+		nb += y * y
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+var (
+	ErrExperimentNotFound = errors.New("experiment not found")
+	// ErrExperimentConflict — для target уже запущен другой эксперимент.
+	ErrExperimentConflict = errors.New("another experiment is active for target")
+)
+
+type ExperimentUsecase interface {
+	Create(ctx context.Context, exp entity.Experiment) (*entity.Experiment, error)
+	Get(ctx context.Context, id string) (*entity.Experiment, error)
+	List(ctx context.Context) ([]*entity.Experiment, error)
+	Stop(ctx context.Context, id string) (*entity.Experiment, error)
+	Delete(ctx context.Context, id string) error
+	// Assign относит задачу к варианту активного эксперимента её target.
+	// Задачи с явно запрошенным промптом в эксперименты не попадают.
+	Assign(ctx context.Context, job *entity.Job) (*entity.ExperimentVariant, error)
+	Summary(ctx context.Context, id string) (*entity.ExperimentSummary, error)
+}
+
+var _ ExperimentUsecase = (*ExperimentService)(nil)
+
+type ExperimentService struct {
+	repo    repository.ExperimentRepository
+	jobs    repository.JobRepository
+	prompts PromptUsecase
+}
+
+func NewExperimentService(repo repository.ExperimentRepository, jobs repository.JobRepository, prompts PromptUsecase) *ExperimentService {
+	return &ExperimentService{repo: repo, jobs: jobs, prompts: prompts}
+}
+
+func (s *ExperimentService) Create(ctx context.Context, exp entity.Experiment) (*entity.Experiment, error) {
+	if strings.TrimSpace(exp.Target) == "" {
+		return nil, fmt.Errorf("experiment target is required")
+	}
+	if len(exp.Variants) < 2 {
+		return nil, fmt.Errorf("experiment needs at least two variants")
+	}
+
+	total := 0
+	names := make(map[string]bool, len(exp.Variants))
+	for _, v := range exp.Variants {
+		if v.Name == "" || names[v.Name] {
+			return nil, fmt.Errorf("variant names must be unique and non-empty: %q", v.Name)
+		}
+		names[v.Name] = true
+		if v.Weight <= 0 {
+			return nil, fmt.Errorf("variant %s: weight must be positive", v.Name)
+		}
+		total += v.Weight
+		if _, err := s.prompts.Get(ctx, v.PromptID, v.PromptVersion); err != nil {
+			return nil, fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+	}
+	if total != 100 {
+		return nil, fmt.Errorf("variant weights must sum to 100, got %d", total)
+	}
+
+	active, err := s.repo.ActiveForTarget(ctx, exp.Target)
+	if err != nil {
+		return nil, fmt.Errorf("get active experiment for %s: %w", exp.Target, err)
+	}
+	if active != nil {
+		return nil, fmt.Errorf("%w %s: %s", ErrExperimentConflict, exp.Target, active.ID)
+	}
+
+	exp.ID = uuid.New().String()
+	exp.Active = true
+	if exp.Name == "" {
+		exp.Name = exp.ID
+	}
+	if err := s.repo.Create(ctx, &exp); err != nil {
+		return nil, fmt.Errorf("create experiment: %w", err)
+	}
+	return &exp, nil
+}
+
+func (s *ExperimentService) Get(ctx context.Context, id string) (*entity.Experiment, error) {
+	exp, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get experiment %s: %w", id, err)
+	}
+	if exp == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExperimentNotFound, id)
+	}
+	return exp, nil
+}
+
+func (s *ExperimentService) List(ctx context.Context) ([]*entity.Experiment, error) {
+	exps, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list experiments: %w", err)
+	}
+	return exps, nil
+}
+
+func (s *ExperimentService) Stop(ctx context.Context, id string) (*entity.Experiment, error) {
+	exp, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	exp.Active = false
+	if err := s.repo.Update(ctx, exp); err != nil {
+		return nil, fmt.Errorf("stop experiment %s: %w", id, err)
+	}
+	return exp, nil
+}
+
+func (s *ExperimentService) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete experiment %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *ExperimentService) Assign(ctx context.Context, job *entity.Job) (*entity.ExperimentVariant, error) {
+	if job.ExperimentID != "" {
+		// задача уже в эксперименте (повторная обработка) — вариант не меняется
+		exp, err := s.repo.GetByID(ctx, job.ExperimentID)
+		if err != nil || exp == nil {
+			return nil, err
+		}
+		for i := range exp.Variants {
+			if exp.Variants[i].Name == job.Variant {
+				return &exp.Variants[i], nil
+			}
+		}
+		return nil, nil
+	}
+	if job.PromptID != "" {
+		return nil, nil
+	}
+
+	exp, err := s.repo.ActiveForTarget(ctx, job.Target)
+	if err != nil {
+		return nil, fmt.Errorf("get active experiment for %s: %w", job.Target, err)
+	}
+	if exp == nil || len(exp.Variants) == 0 {
+		return nil, nil
+	}
+
+	variant := pickVariant(exp.Variants, job.ID)
+	job.ExperimentID = exp.ID
+	job.Variant = variant.Name
+	return variant, nil
+}
+
+// pickVariant детерминированно выбирает вариант по id задачи: одна и та же
+// задача всегда попадает в один вариант, а доли в среднем совпадают с весами.
+func pickVariant(variants []entity.ExperimentVariant, jobID string) *entity.ExperimentVariant {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(jobID))
+	bucket := int(h.Sum32() % uint32(total))
+
+	for i := range variants {
+		bucket -= variants[i].Weight
+		if bucket < 0 {
+			return &variants[i]
+		}
+	}
+	return &variants[len(variants)-1]
+}
+
+func (s *ExperimentService) Summary(ctx context.Context, id string) (*entity.ExperimentSummary, error) {
+	exp, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := s.jobs.ListByExperiment(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list experiment jobs: %w", err)
+	}
+
+	byVariant := make(map[string][]*entity.Job, len(exp.Variants))
+	for _, job := range jobs {
+		byVariant[job.Variant] = append(byVariant[job.Variant], job)
+	}
+
+	summary := &entity.ExperimentSummary{Experiment: exp}
+	for _, v := range exp.Variants {
+		summary.Variants = append(summary.Variants, variantOutcome(v.Name, byVariant[v.Name]))
+	}
+	return summary, nil
+}
+
+func variantOutcome(name string, jobs []*entity.Job) *entity.VariantOutcome {
+	out := &entity.VariantOutcome{Variant: name, Jobs: len(jobs)}
+	if len(jobs) == 0 {
+		return out
+	}
+
+	var static, sandbox, deploy passCounter
+	var repairs, tokens int64
+	for _, job := range jobs {
+		static.add(job.StaticPassed)
+		sandbox.add(job.SandboxPassed)
+		switch {
+		case job.DeployError != "":
+			deploy.add(boolPtr(false))
+		case job.Status == entity.JobStatusDeployed:
+			deploy.add(boolPtr(true))
+		}
+		repairs += int64(job.RepairRounds)
+		tokens += job.Usage.TotalTokens
+	}
+
+	out.StaticPassRate = static.rate()
+	out.SandboxPassRate = sandbox.rate()
+	out.DeploySuccessRate = deploy.rate()
+	out.AvgRepairRounds = float64(repairs) / float64(len(jobs))
+	out.AvgTokens = float64(tokens) / float64(len(jobs))
+	return out
+}
+
+type passCounter struct {
+	passed, total int
+}
+
+func (c *passCounter) add(passed *bool) {
+	if passed == nil {
+		return
+	}
+	c.total++
+	if *passed {
+		c.passed++
+	}
+}
+
+func (c *passCounter) rate() *float64 {
+	if c.total == 0 {
+		return nil
+	}
+	r := float64(c.passed) / float64(c.total)
+	return &r
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
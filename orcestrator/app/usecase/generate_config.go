@@ -2,13 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"orchestrator/internal/domain/entity"
 	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/llm"
 	"orchestrator/internal/infrastructure/store/filesystem"
 	"orchestrator/internal/infrastructure/validator"
 )
@@ -30,16 +33,23 @@ type ConfigGeneratorService struct {
 	configRepo     repository.ConfgiFileRepository
 	configFileRepo filesystem.FileRepository
 	llm            repository.LLMGenerator
+	budget         BudgetUsecase
+	prompts        PromptUsecase
+	experiments    ExperimentUsecase
+	modules        ModuleCatalogUsecase
+	examples       ExampleUsecase
+	reports        repository.ValidationReportRepository
 
-	staticVal validator.TerraformAnalyzer // Dependency on external circles
-	// sandboxVal  Validator
-	// securityVal Validator
+	checker     *RevisionChecker // Dependency on external circles
+	sandboxVal  Validator
+	securityVal Validator
 
 	logger *slog.Logger
 
 	pollInterval      time.Duration
 	validationTimeout time.Duration
 	maxRetries        int
+	maxCandidates     int
 
 	// control
 	stop    chan struct{}
@@ -51,24 +61,38 @@ func NewConfigGeneratorService(
 	cr repository.ConfgiFileRepository,
 	cfr filesystem.FileRepository,
 	llm repository.LLMGenerator,
-	staticVal validator.TerraformAnalyzer,
+	budget BudgetUsecase,
+	prompts PromptUsecase,
+	experiments ExperimentUsecase,
+	modules ModuleCatalogUsecase,
+	examples ExampleUsecase,
+	reports repository.ValidationReportRepository,
+	checker *RevisionChecker,
 	sandboxVal Validator,
 	securityVal Validator,
+	maxCandidates int,
 	logger *slog.Logger,
 ) *ConfigGeneratorService {
 	pi := 5 * time.Second
 	return &ConfigGeneratorService{
-		jobsRepo:       jr,
-		configRepo:     cr,
-		configFileRepo: cfr,
-		llm:            llm,
-		staticVal:      staticVal,
-		// sandboxVal:        sandboxVal,
-		// securityVal:       securityVal,
+		jobsRepo:          jr,
+		configRepo:        cr,
+		configFileRepo:    cfr,
+		llm:               llm,
+		budget:            budget,
+		prompts:           prompts,
+		experiments:       experiments,
+		modules:           modules,
+		examples:          examples,
+		reports:           reports,
+		checker:           checker,
+		sandboxVal:        sandboxVal,
+		securityVal:       securityVal,
 		logger:            logger,
 		pollInterval:      pi,
 		validationTimeout: 30 * time.Minute,
 		maxRetries:        3,
+		maxCandidates:     maxCandidates,
 		stop:              make(chan struct{}),
 		stopped:           make(chan struct{}),
 	}
@@ -114,6 +138,15 @@ func (s *ConfigGeneratorService) runOnce(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("list pending jobs: %w", err)
 	}
+	queued, err := s.jobsRepo.ListByStatus(ctx, entity.JobStatusQueued)
+	if err != nil {
+		return fmt.Errorf("list queued jobs: %w", err)
+	}
+	refining, err := s.jobsRepo.ListByStatus(ctx, entity.JobStatusRefining)
+	if err != nil {
+		return fmt.Errorf("list refining jobs: %w", err)
+	}
+	jobs = append(append(queued, jobs...), refining...)
 	if len(jobs) == 0 {
 		return nil
 	}
@@ -121,6 +154,22 @@ func (s *ConfigGeneratorService) runOnce(ctx context.Context) error {
 	s.logger.Debug("found pending jobs", "count", len(jobs))
 
 	for _, job := range jobs {
+		refine := job.Status == entity.JobStatusRefining
+		if err := s.budget.Check(ctx, job.Owner); err != nil {
+			if !errors.Is(err, ErrBudgetExceeded) {
+				s.logger.Warn("budget check failed; skip", "job_id", job.ID, "err", err)
+				continue
+			}
+			// бюджет исчерпан — задача ждёт следующего окна; доработка остаётся в refining
+			if job.Status != entity.JobStatusQueued && !refine {
+				if err := s.jobsRepo.UpdateStatus(ctx, job.ID, entity.JobStatusQueued); err != nil {
+					s.logger.Warn("failed to set job queued", "job_id", job.ID, "err", err)
+				}
+			}
+			s.logger.Debug("job queued until next budget window", "job_id", job.ID, "owner", job.Owner)
+			continue
+		}
+
 		if err := s.jobsRepo.UpdateStatus(ctx, job.ID, entity.JobStatusRunning); err != nil {
 			// если не можем обновить статус — пропускаем и логируем
 			s.logger.Warn("failed to set job running; skip", "job_id", job.ID, "err", err)
@@ -130,6 +179,12 @@ func (s *ConfigGeneratorService) runOnce(ctx context.Context) error {
 		procCtx, cancel := context.WithTimeout(ctx, s.validationTimeout)
 		func() {
 			defer cancel()
+			if refine {
+				if err := s.refineJob(procCtx, job); err != nil {
+					s.logger.Error("refineJob failed", "job_id", job.ID, "err", err)
+				}
+				return
+			}
 			if err := s.processJob(procCtx, job); err != nil {
 				s.logger.Error("processJob failed", "job_id", job.ID, "err", err)
 			}
@@ -151,58 +206,509 @@ func (s *ConfigGeneratorService) processJob(ctx context.Context, job *entity.Job
 	s.logger.Info("start processing job", "job_id", jobID)
 
 	// 1) Generate via LLM
-	generatedResponse, err := s.llm.GenerateInfrastructure(ctx, job.Description, entity.TerraformPrompt)
+	genCtx := ctx
+	ref := entity.PromptRef{ID: job.PromptID, Version: job.PromptVersion}
+	variant, err := s.experiments.Assign(ctx, job)
+	if err != nil {
+		// эксперимент не должен ломать генерацию — работаем с промптом по умолчанию
+		s.logger.Warn("experiment assignment failed", "job_id", jobID, "err", err)
+	}
+	if variant != nil {
+		s.logger.Info("job assigned to experiment", "job_id", jobID, "experiment_id", job.ExperimentID, "variant", variant.Name)
+		ref = entity.PromptRef{ID: variant.PromptID, Version: variant.PromptVersion}
+		if variant.Model != "" {
+			genCtx = llm.WithModel(genCtx, variant.Model)
+		}
+	}
+
+	prompt, err := s.prompts.Resolve(ctx, job.Target, ref)
+	if err != nil {
+		_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed)
+		s.logger.Error("resolve prompt failed", "job_id", jobID, "target", job.Target, "err", err)
+		return fmt.Errorf("resolve prompt: %w", err)
+	}
+	job.PromptID, job.PromptVersion = prompt.ID, prompt.Version
+
+	if job.ClarifyFirst && !job.Clarified && s.askQuestions(genCtx, job, *prompt) {
+		s.logger.Info("job awaiting answers", "job_id", jobID, "questions", len(job.Questions))
+		return nil
+	}
+
+	if job.TwoPhase && job.Spec == nil {
+		s.extractSpec(genCtx, job, *prompt)
+	}
+
+	job.InjectedSnippets = nil
+	genPrompt := s.withModules(job, *prompt, job.Requirements())
+	genPrompt = s.withExamples(ctx, job, genPrompt)
+
+	if job.NoCache {
+		genCtx = llm.WithoutCache(genCtx)
+	}
+	responses, err := s.generateCandidates(genCtx, job, genPrompt)
 	if err != nil {
 		_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed)
 		s.logger.Error("llm generation failed", "job_id", jobID, "err", err)
 		return fmt.Errorf("llm generate: %w", err)
 	}
-	for i := range generatedResponse.Files {
-		generatedResponse.Files[i].JobID = jobID
+	for _, resp := range responses[1:] {
+		s.recordUsage(ctx, job, resp.Model, resp.Usage)
 	}
+	s.recordGeneration(ctx, job, responses[0])
 
-	// 2) Save generated files
-	if err := s.configRepo.SaveFiles(ctx, generatedResponse.Files); err != nil {
+	// каждый кандидат — отдельная ревизия; повторная генерация (например, после
+	// правки спецификации) продолжает нумерацию
+	candidates := make([]*candidate, 0, len(responses))
+	for i, resp := range responses {
+		c, err := s.evaluateRevision(ctx, job, job.NextRevision()+i, resp.Files, resp.ParseErrors)
+		if err != nil {
+			_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed)
+			return err
+		}
+		c.result.Tokens = resp.Usage.TotalTokens
+		candidates = append(candidates, c)
+	}
+	best := pickCandidate(candidates)
+
+	job.Alternatives = nil
+	if len(candidates) > 1 {
+		for _, c := range candidates {
+			c.result.Selected = c == best
+			job.Alternatives = append(job.Alternatives, c.result)
+		}
+		s.logger.Info("best candidate selected", "job_id", jobID, "revision", best.result.Revision,
+			"score", best.result.Score, "candidates", len(candidates))
+	}
+
+	note := revisionNote("Generated", best.files)
+	if len(candidates) > 1 {
+		note = fmt.Sprintf("%s Best of %d candidates (revisions %d-%d).", note, len(candidates),
+			candidates[0].result.Revision, candidates[len(candidates)-1].result.Revision)
+	}
+	job.Conversation = append(job.Conversation,
+		entity.ChatMessage{Role: entity.ChatRoleUser, Content: job.Requirements(), Revision: best.result.Revision, CreatedAt: time.Now()},
+		entity.ChatMessage{Role: entity.ChatRoleAssistant, Content: note, Revision: best.result.Revision, CreatedAt: time.Now()},
+	)
+
+	s.activateRevision(ctx, job, best)
+
+	s.logger.Info("job processed", "job_id", jobID, "duration", time.Since(startTime))
+	return nil
+}
+
+// generateCandidates запрашивает у модели N независимых вариантов файлов параллельно.
+// Первый кандидат может прийти из кэша, остальные всегда генерируются заново.
+// Ошибка возвращается, только если не удался ни один кандидат.
+func (s *ConfigGeneratorService) generateCandidates(ctx context.Context, job *entity.Job, prompt entity.Prompt) ([]entity.GenerateResponse, error) {
+	n := job.Candidates
+	if n < 1 {
+		n = 1
+	}
+	if s.maxCandidates > 0 && n > s.maxCandidates {
+		s.logger.Info("candidates capped", "job_id", job.ID, "requested", n, "max", s.maxCandidates)
+		n = s.maxCandidates
+	}
+
+	responses := make([]entity.GenerateResponse, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			genCtx := ctx
+			if i > 0 {
+				genCtx = llm.WithoutCache(ctx)
+			}
+			responses[i], errs[i] = s.llm.GenerateInfrastructure(genCtx, job.Requirements(), prompt)
+		}(i)
+	}
+	wg.Wait()
+
+	var ok []entity.GenerateResponse
+	for i := range responses {
+		if errs[i] != nil {
+			s.logger.Warn("candidate generation failed", "job_id", job.ID, "candidate", i+1, "err", errs[i])
+			continue
+		}
+		ok = append(ok, responses[i])
+	}
+	if len(ok) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return ok, nil
+}
+
+// refineJob дорабатывает текущую ревизию файлов по последней инструкции пользователя
+// и сохраняет результат как новую ревизию.
+func (s *ConfigGeneratorService) refineJob(ctx context.Context, job *entity.Job) error {
+	startTime := time.Now()
+	jobID := job.ID
+
+	pending := pendingInstruction(job)
+	if pending < 0 {
+		_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusReady2Deploy)
+		return fmt.Errorf("job %s has no pending instruction", jobID)
+	}
+	instruction := job.Conversation[pending].Content
+
+	s.logger.Info("start refining job", "job_id", jobID, "revision", job.Revision)
+
+	prompt, err := s.prompts.Resolve(ctx, job.Target, entity.PromptRef{ID: job.PromptID, Version: job.PromptVersion})
+	if err != nil {
 		_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed)
-		s.logger.Error("save files failed", "job_id", jobID, "err", err)
-		return fmt.Errorf("save files: %w", err)
+		return fmt.Errorf("resolve prompt: %w", err)
+	}
+
+	current, err := s.configRepo.GetFilesByRevision(ctx, jobID, job.Revision)
+	if err != nil {
+		_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed)
+		return fmt.Errorf("get files of revision %d: %w", job.Revision, err)
 	}
 
-	if err := s.configFileRepo.SaveFiles(ctx, generatedResponse.Files, jobID); err != nil {
-		s.logger.Error("save files to local failed", "job_id", jobID, "err", err)
+	// доработка идёт той же моделью, что и исходная генерация
+	genCtx := llm.WithModel(ctx, job.Model)
+	genPrompt := s.withModules(job, *prompt, job.Description+"\n"+instruction)
+	resp, err := s.llm.RefineInfrastructure(genCtx, configFileValues(current), job.Conversation[:pending], instruction, genPrompt)
+	if err != nil {
+		_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed)
+		s.logger.Error("llm refinement failed", "job_id", jobID, "err", err)
+		return fmt.Errorf("llm refine: %w", err)
 	}
+	s.recordGeneration(ctx, job, resp)
 
-	// 3) Static validation
-	workDir := filepath.Join(s.configFileRepo.GetBasePath(), jobID)
+	job.Revision = job.NextRevision()
+	job.RepairRounds++
+	job.Conversation[pending].Revision = job.Revision
+	job.Conversation = append(job.Conversation, entity.ChatMessage{
+		Role:      entity.ChatRoleAssistant,
+		Content:   revisionNote("Updated", resp.Files),
+		Revision:  job.Revision,
+		CreatedAt: time.Now(),
+	})
 
-	staticRes, err := s.staticVal.Analyze(generatedResponse.Files, workDir)
+	c, err := s.evaluateRevision(ctx, job, job.Revision, mergeRevision(current, resp.Files), resp.ParseErrors)
 	if err != nil {
 		_ = s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed)
-		s.logger.Error("static validator error", "job_id", jobID, "err", err)
+		return err
+	}
+	s.activateRevision(ctx, job, c)
+
+	s.logger.Info("job refined", "job_id", jobID, "revision", job.Revision, "duration", time.Since(startTime))
+	return nil
+}
+
+// withModules дополняет промпт подходящими модулями каталога организации, чтобы модель
+// вызывала их, а не описывала те же ресурсы заново, и записывает их на задачу.
+func (s *ConfigGeneratorService) withModules(job *entity.Job, prompt entity.Prompt, query string) entity.Prompt {
+	catalog, snippets := s.modules.Context(query)
+	if catalog == "" {
+		return prompt
 	}
+	prompt.Text += "\n\n" + catalog
 
-	markFilesWithErrors(generatedResponse.Files, staticRes.Errors)
-	if err := s.configRepo.SaveFiles(ctx, generatedResponse.Files); err != nil {
-		s.logger.Error("resave files with errors failed", "job_id", jobID, "err", err)
+	for _, snippet := range snippets {
+		known := false
+		for _, injected := range job.InjectedSnippets {
+			known = known || injected.Source == snippet.Source
+		}
+		if !known {
+			job.InjectedSnippets = append(job.InjectedSnippets, snippet)
+		}
+	}
+	s.logger.Info("module catalog injected", "job_id", job.ID, "modules", len(snippets))
+	return prompt
+}
+
+// withExamples добавляет к промпту few-shot примеры из прошлых успешных задач.
+func (s *ConfigGeneratorService) withExamples(ctx context.Context, job *entity.Job, prompt entity.Prompt) entity.Prompt {
+	fewShot, ids := s.examples.FewShot(ctx, job)
+	job.FewShotExamples = ids
+	if fewShot == "" {
+		return prompt
+	}
+	prompt.Text += "\n\n" + fewShot
+	s.logger.Info("few-shot examples injected", "job_id", job.ID, "examples", len(ids))
+	return prompt
+}
+
+// askQuestions запрашивает у модели недостающие параметры. Возвращает true, если
+// задача переведена в awaiting_input и генерация должна подождать ответов.
+// Ошибка уточнения не блокирует генерацию: задача продолжается по исходному описанию.
+func (s *ConfigGeneratorService) askQuestions(ctx context.Context, job *entity.Job, prompt entity.Prompt) bool {
+	resp, err := s.llm.ClarifyRequirements(ctx, job.Description, prompt)
+	s.recordUsage(ctx, job, resp.Model, resp.Usage)
+	if err != nil {
+		s.logger.Warn("clarifying questions failed; generating without them", "job_id", job.ID, "err", err)
+		job.Clarified = true
+		return false
+	}
+	if len(resp.Questions) == 0 {
+		job.Clarified = true
+		return false
+	}
+
+	job.Questions = resp.Questions
+	job.UpdateStatus(entity.JobStatusAwaitingInput)
+	if err := s.jobsRepo.Update(ctx, job); err != nil {
+		s.logger.Error("failed to save clarifying questions", "job_id", job.ID, "err", err)
+		_ = s.jobsRepo.UpdateStatus(ctx, job.ID, entity.JobStatusFailed)
+	}
+	return true
+}
+
+// extractSpec — первая фаза двухфазной генерации: структурированная спецификация
+// из описания. Если модель не вернула спецификацию, генерация идёт по тексту.
+func (s *ConfigGeneratorService) extractSpec(ctx context.Context, job *entity.Job, prompt entity.Prompt) {
+	resp, err := s.llm.ExtractSpec(ctx, job.Requirements(), prompt)
+	s.recordUsage(ctx, job, resp.Model, resp.Usage)
+	if err != nil || resp.Spec == nil {
+		s.logger.Warn("spec extraction failed; generating from description", "job_id", job.ID, "err", err)
+		return
+	}
+	job.Spec = resp.Spec
+	s.logger.Info("spec extracted", "job_id", job.ID, "cloud", job.Spec.Cloud, "region", job.Spec.Region, "components", len(job.Spec.Components))
+}
+
+// candidate — ревизия файлов вместе с итогами её валидации.
+type candidate struct {
+	files  []*entity.ConfigFile
+	result entity.CandidateResult
+}
+
+// evaluateRevision сохраняет файлы ревизии и прогоняет по ним валидаторы.
+// Артефакты валидаторов пишутся в каталог ревизии.
+func (s *ConfigGeneratorService) evaluateRevision(
+	ctx context.Context,
+	job *entity.Job,
+	revision int,
+	files []*entity.ConfigFile,
+	parseErrors []*entity.ValidationConfigError,
+) (*candidate, error) {
+	jobID := job.ID
+	for _, f := range files {
+		f.JobID = jobID
+		f.Revision = revision
+	}
+
+	// канонический вид до сохранения: иначе terraform fmt -check падает на отступах модели
+	formatErrors := validator.FormatFiles(files)
+
+	// 2) Save generated files
+	if err := s.configRepo.SaveFiles(ctx, files); err != nil {
+		s.logger.Error("save files failed", "job_id", jobID, "err", err)
+		return nil, fmt.Errorf("save files: %w", err)
+	}
+
+	// 3) Static validation
+	workDir := revisionDir(s.configFileRepo.GetBasePath(), jobID, revision)
+
+	// проблемы разбора ответа модели — такие же замечания валидации
+	for _, e := range parseErrors {
+		if e.RuleID == "" {
+			e.RuleID = validator.RuleLLMOutput
+		}
+	}
+	if len(parseErrors) > 0 {
+		s.logger.Warn("llm output parse problems", "job_id", jobID, "count", len(parseErrors))
+	}
+	staticRes := s.checker.Check(ctx, job, files, append(parseErrors, formatErrors...), workDir)
+
+	markFilesWithErrors(files, staticRes.Errors)
+
+	c := &candidate{
+		files: files,
+		result: entity.CandidateResult{
+			Revision:     revision,
+			StaticPassed: staticRes.Passed,
+			Findings:     len(staticRes.Errors),
+		},
+	}
+	c.result.Inputs = staticRes.Inputs
+	c.result.Suppressed = suppressedFindings(staticRes)
+
+	// 4) Sandbox и security валидация — только для статически корректных файлов
+	if staticRes.Passed {
+		c.result.SandboxPassed = s.runValidator(ctx, jobID, s.sandboxVal, files)
+		c.result.SecurityPassed = s.runValidator(ctx, jobID, s.securityVal, files)
+	}
+	c.result.Score = candidateScore(c.result)
+
+	// замечания хранятся отчётом ревизии, а не повторной записью файлов
+	report := revisionReport(jobID, revision, files, staticRes)
+	report.SandboxPassed = c.result.SandboxPassed
+	report.SecurityPassed = c.result.SecurityPassed
+	if err := s.reports.Save(ctx, report); err != nil {
+		s.logger.Error("save validation report failed", "job_id", jobID, "revision", revision, "err", err)
+	}
+	return c, nil
+}
+
+func (s *ConfigGeneratorService) runValidator(ctx context.Context, jobID string, v Validator, files []*entity.ConfigFile) *bool {
+	if v == nil {
+		return nil
+	}
+	res, err := v.Validate(ctx, configFileValues(files))
+	if err != nil {
+		s.logger.Error("validator error", "job_id", jobID, "validator", v.Name(), "err", err)
+		return nil
+	}
+	return &res.Passed
+}
+
+// activateRevision делает ревизию текущей: выкладывает её файлы в каталог деплоя
+// и переводит задачу в ready_to_deploy.
+func (s *ConfigGeneratorService) activateRevision(ctx context.Context, job *entity.Job, c *candidate) {
+	if err := s.configFileRepo.ReplaceFiles(ctx, c.files, job.ID); err != nil {
+		s.logger.Error("save files to local failed", "job_id", job.ID, "err", err)
+	}
+
+	job.Revision = c.result.Revision
+	job.StaticPassed = &c.result.StaticPassed
+	job.SandboxPassed = c.result.SandboxPassed
+	job.Suppressed = c.result.Suppressed
+	job.RequiredInputs = providedInputs(ctx, s.configFileRepo, job.ID, c.result.Inputs)
+	if err := s.jobsRepo.Update(ctx, job); err != nil {
+		s.logger.Warn("failed to save job outcomes", "job_id", job.ID, "err", err)
 	}
 
 	// 6) Всё прошло - помечаем ready_to_deploy
-	if err := s.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusReady2Deploy); err != nil {
-		s.logger.Warn("failed to update job to ready_to_deploy", "job_id", jobID, "err", err)
+	if err := s.jobsRepo.UpdateStatus(ctx, job.ID, entity.JobStatusReady2Deploy); err != nil {
+		s.logger.Warn("failed to update job to ready_to_deploy", "job_id", job.ID, "err", err)
 	}
 
-	s.logger.Info("job processed", "job_id", jobID, "duration", time.Since(startTime))
-	return nil
+	// ревизия без единого замечания — хороший пример для следующих задач
+	r := c.result
+	if r.StaticPassed && r.Findings == 0 && (r.SandboxPassed == nil || *r.SandboxPassed) && (r.SecurityPassed == nil || *r.SecurityPassed) {
+		if err := s.examples.Capture(ctx, job, c.files, entity.ExampleSourceValidated); err != nil {
+			s.logger.Warn("failed to capture example", "job_id", job.ID, "err", err)
+		}
+	}
+}
+
+// candidateScore ранжирует кандидатов: пройденные этапы важнее числа замечаний.
+func candidateScore(r entity.CandidateResult) int {
+	score := 0
+	if r.StaticPassed {
+		score += 100
+	}
+	if r.SandboxPassed != nil && *r.SandboxPassed {
+		score += 100
+	}
+	if r.SecurityPassed != nil && *r.SecurityPassed {
+		score += 50
+	}
+	return score - r.Findings
+}
+
+// pickCandidate выбирает кандидата с лучшим счётом; при равенстве — более дешёвого, затем первого.
+func pickCandidate(candidates []*candidate) *candidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.result.Score > best.result.Score ||
+			(c.result.Score == best.result.Score && c.result.Tokens < best.result.Tokens) {
+			best = c
+		}
+	}
+	return best
+}
+
+// recordGeneration списывает расход токенов с бюджета владельца и сохраняет
+// на задаче расход, модель, промпт и признак ответа из кэша.
+func (s *ConfigGeneratorService) recordGeneration(ctx context.Context, job *entity.Job, resp entity.GenerateResponse) {
+	s.recordUsage(ctx, job, resp.Model, resp.Usage)
+	job.CacheHit = resp.CacheHit
+	job.Model = resp.Model
+	job.UpdateStatus(entity.JobStatusRunning)
+	if err := s.jobsRepo.Update(ctx, job); err != nil {
+		s.logger.Warn("failed to save job usage", "job_id", job.ID, "err", err)
+	}
+}
+
+// pendingInstruction возвращает индекс ещё не обработанной инструкции пользователя или -1.
+func pendingInstruction(job *entity.Job) int {
+	for i := len(job.Conversation) - 1; i >= 0; i-- {
+		m := job.Conversation[i]
+		if m.Role == entity.ChatRoleUser && m.Revision == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeRevision собирает новую ревизию: файлы из ответа модели заменяют
+// одноимённые, остальные файлы переносятся без изменений.
+func mergeRevision(current, updated []*entity.ConfigFile) []*entity.ConfigFile {
+	byName := make(map[string]*entity.ConfigFile, len(updated))
+	for _, f := range updated {
+		byName[f.Name] = f
+	}
+
+	merged := make([]*entity.ConfigFile, 0, len(current)+len(updated))
+	for _, f := range current {
+		if u, ok := byName[f.Name]; ok {
+			merged = append(merged, u)
+			delete(byName, f.Name)
+			continue
+		}
+		c := *f
+		merged = append(merged, &c)
+	}
+	for _, f := range updated {
+		if _, ok := byName[f.Name]; ok {
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
+
+func revisionNote(verb string, files []*entity.ConfigFile) string {
+	if len(files) == 0 {
+		return "No files changed."
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return fmt.Sprintf("%s files: %s.", verb, strings.Join(names, ", "))
 }
 
-func markFilesWithErrors(files []*entity.ConfigFile, errors []*entity.ValidationConfigError) {
+func configFileValues(files []*entity.ConfigFile) []entity.ConfigFile {
+	values := make([]entity.ConfigFile, 0, len(files))
+	for _, f := range files {
+		values = append(values, *f)
+	}
+	return values
+}
+
+// recordUsage списывает расход токенов с бюджета владельца и добавляет его к задаче.
+func (s *ConfigGeneratorService) recordUsage(ctx context.Context, job *entity.Job, model string, usage entity.TokenUsage) {
+	if err := s.budget.Record(ctx, job.Owner, job.ID, model, usage); err != nil {
+		s.logger.Warn("failed to record llm usage", "job_id", job.ID, "err", err)
+	}
+	job.Usage.Add(usage)
+}
+
+func fileNames(files []*entity.ConfigFile) []string {
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// markFilesWithErrors раскладывает замечания по файлам; HasError ставят только ошибки.
+func markFilesWithErrors(files []*entity.ConfigFile, findings []*entity.ValidationConfigError) {
 	for _, file := range files {
 		file.HasError = false
-		file.ErrorMsg = nil
-		for _, err := range errors {
-			if err.File == file.Name {
+		file.Findings = nil
+		for _, f := range findings {
+			if f.File != file.Name {
+				continue
+			}
+			file.Findings = append(file.Findings, *f)
+			if f.Severity == entity.SeverityError || f.Severity == "" {
 				file.HasError = true
-				file.ErrorMsg = err
 			}
 		}
 	}
@@ -2,38 +2,118 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"orchestrator/internal/domain/entity"
 	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/store/filesystem"
+	"orchestrator/internal/infrastructure/validator"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotRefinable — задачу нельзя дорабатывать, пока нет готовых файлов или идёт обработка.
+	ErrJobNotRefinable = errors.New("job cannot be refined in its current state")
+	// ErrJobNotAwaitingInput — на вопросы можно отвечать только в статусе awaiting_input.
+	ErrJobNotAwaitingInput = errors.New("job is not awaiting input")
+	ErrInvalidAnswers      = errors.New("invalid answers")
+	// ErrJobBusy — задача сейчас обрабатывается воркером.
+	ErrJobBusy          = errors.New("job is being processed")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrInvalidRating    = errors.New("rating must be between 1 and 5")
+	// ErrInputsRequired — в файлах остались плейсхолдеры, значения для которых не переданы.
+	ErrInputsRequired = errors.New("required inputs are missing")
+	ErrInvalidInputs  = errors.New("invalid inputs")
+	// ErrReportNotFound — для текущей ревизии задачи нет отчёта статического анализа.
+	ErrReportNotFound = errors.New("static analysis report not found")
+	// ErrValidationReportNotFound — ревизия ещё не проверялась.
+	ErrValidationReportNotFound = errors.New("validation report not found")
+	ErrInvalidAutofix           = errors.New("invalid autofix request")
+	// ErrRegenerateRequired — правка спецификации развёрнутой задачи перезапишет её файлы,
+	// поэтому требует явного подтверждения.
+	ErrRegenerateRequired = errors.New("job is deployed; pass regenerate to rebuild it from the new spec")
 )
 
 type JobUsecase interface {
-	CreateJob(ctx context.Context, description, target string) error
+	CreateJob(ctx context.Context, job *entity.Job) error
 	GetJob(ctx context.Context, id string) (*entity.Job, error)
 	ListJobs(ctx context.Context) ([]*entity.Job, error)
 	UpdateStatus(ctx context.Context, jobID string, status entity.JobStatus) error
 	DeleteJob(ctx context.Context, jobID string) error
 	DeployJob(ctx context.Context, jobID string) error
+	// RefineJob ставит в очередь доработку файлов задачи по инструкции пользователя.
+	RefineJob(ctx context.Context, jobID, instruction string) (*entity.Job, error)
+	// AnswerQuestions сохраняет ответы на уточняющие вопросы и возвращает задачу в очередь генерации.
+	AnswerQuestions(ctx context.Context, jobID string, answers map[string]string) (*entity.Job, error)
+	// UpdateSpec заменяет спецификацию задачи и перезапускает генерацию по ней.
+	// Развёрнутая задача перегенерируется только с regenerate.
+	UpdateSpec(ctx context.Context, jobID string, spec *entity.InfraSpec, regenerate bool) (*entity.Job, error)
+	// ActivateRevision делает текущей другую ревизию файлов (например, альтернативного кандидата).
+	ActivateRevision(ctx context.Context, jobID string, revision int) (*entity.Job, error)
+	// RateJob сохраняет оценку результата пользователем (1..5).
+	RateJob(ctx context.Context, jobID string, rating int) (*entity.Job, error)
+	// EditFiles сохраняет ручную правку файлов как новую ревизию. Файлы, которых нет в edits,
+	// переносятся без изменений; файл с пустым содержимым удаляется.
+	EditFiles(ctx context.Context, jobID string, edits []entity.ConfigFile) (*entity.Job, error)
+	// SetInputs сохраняет значения обязательных входных переменных (вместо плейсхолдеров REPLACE_ME).
+	// Значения пишутся рядом с файлами задачи и в самой задаче не хранятся.
+	SetInputs(ctx context.Context, jobID string, values map[string]string) (*entity.Job, error)
+	// StaticReport возвращает отчёт статического анализа текущей ревизии в указанном формате.
+	StaticReport(ctx context.Context, jobID string, reporter validator.Reporter) ([]byte, error)
+	// ValidationReport возвращает замечания валидации ревизии; revision 0 — текущая ревизия задачи.
+	ValidationReport(ctx context.Context, jobID string, revision int) (*entity.ValidationReport, error)
+	// Autofix механически исправляет замечания текущей ревизии и сохраняет результат новой ревизией
+	// без обращения к модели. rules ограничивает правила; при dryRun возвращается только diff.
+	Autofix(ctx context.Context, jobID string, rules []string, dryRun bool) (*AutofixOutcome, error)
+}
+
+// AutofixOutcome — исправления, их diff и задача (с новой ревизией, если исправления применены).
+type AutofixOutcome struct {
+	Job     *entity.Job     `json:"job"`
+	Fixes   []validator.Fix `json:"fixes"`
+	Skipped []validator.Fix `json:"skipped,omitempty"`
+	Diff    string          `json:"diff"`
 }
 
 var _ JobUsecase = (*JobService)(nil)
 
 type JobService struct {
-	jobsRepo   repository.JobRepository
-	configRepo repository.ConfgiFileRepository
-	deployer   Deployer
+	jobsRepo       repository.JobRepository
+	configRepo     repository.ConfgiFileRepository
+	configFileRepo filesystem.FileRepository
+	deployer       Deployer
+	budget         BudgetUsecase
+	examples       ExampleUsecase
+	reports        repository.ValidationReportRepository
+	checker        *RevisionChecker
 }
 
 func NewJobService(
 	jr repository.JobRepository,
 	cr repository.ConfgiFileRepository,
+	cfr filesystem.FileRepository,
 	d Deployer,
+	b BudgetUsecase,
+	examples ExampleUsecase,
+	reports repository.ValidationReportRepository,
+	checker *RevisionChecker,
 ) *JobService {
 	return &JobService{
-		jobsRepo:   jr,
-		configRepo: cr,
-		deployer:   d,
+		jobsRepo:       jr,
+		configRepo:     cr,
+		configFileRepo: cfr,
+		deployer:       d,
+		budget:         b,
+		examples:       examples,
+		reports:        reports,
+		checker:        checker,
 	}
 }
 
@@ -42,19 +122,45 @@ func (u *JobService) DeployJob(ctx context.Context, jobID string) error {
 	if err != nil {
 		return fmt.Errorf("err get job from store: %w", err)
 	}
+	if job == nil {
+		return repositoryNotFoundError(jobID)
+	}
+	if missing := job.MissingInputs(); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrInputsRequired, strings.Join(missing, ", "))
+	}
 	_, err = u.deployer.Deploy(ctx, job)
 	if err != nil {
+		// исход деплоя нужен для сравнения вариантов экспериментов
+		job.DeployError = err.Error()
+		_ = u.jobsRepo.Update(ctx, job)
 		return fmt.Errorf("err deploy job: %w", err)
 	}
-	err = u.jobsRepo.UpdateStatus(ctx, jobID, entity.JobStatusDeployed)
+	job.DeployError = ""
+	job.UpdateStatus(entity.JobStatusDeployed)
+	err = u.jobsRepo.Update(ctx, job)
 	if err != nil {
 		return fmt.Errorf("err update status: %w", err)
 	}
+
+	// развёрнутая задача пополняет базу few-shot примеров; ошибка не влияет на деплой
+	if files, err := u.configRepo.GetFilesByRevision(ctx, job.ID, job.Revision); err == nil {
+		_ = u.examples.Capture(ctx, job, files, entity.ExampleSourceDeployed)
+	}
 	return nil
 }
 
-func (u *JobService) CreateJob(ctx context.Context, description, target string) error {
-	job := entity.NewJob(description, target)
+func (u *JobService) CreateJob(ctx context.Context, job *entity.Job) error {
+	job.Owner = ownerOrDefault(job.Owner)
+
+	if err := u.budget.Check(ctx, job.Owner); err != nil {
+		if !errors.Is(err, ErrBudgetExceeded) {
+			return fmt.Errorf("check budget: %w", err)
+		}
+		if u.budget.Policy() == entity.BudgetPolicyReject {
+			return err
+		}
+		job.Status = entity.JobStatusQueued
+	}
 
 	if err := u.jobsRepo.Create(ctx, job); err != nil { // Заменить на событие в Kafka
 		return fmt.Errorf("create job: %w", err)
@@ -87,6 +193,9 @@ func (u *JobService) DeleteJob(ctx context.Context, jobID string) error {
 	if err := u.configRepo.DeleteRequest(ctx, jobID); err != nil {
 		return fmt.Errorf("delete config files: %w", err)
 	}
+	if err := u.reports.DeleteByJob(ctx, jobID); err != nil {
+		return fmt.Errorf("delete validation reports: %w", err)
+	}
 	if err := u.jobsRepo.Delete(ctx, jobID); err != nil {
 		return fmt.Errorf("delete job: %w", err)
 	}
@@ -94,6 +203,458 @@ func (u *JobService) DeleteJob(ctx context.Context, jobID string) error {
 	return nil
 }
 
+func (u *JobService) RefineJob(ctx context.Context, jobID, instruction string) (*entity.Job, error) {
+	instruction = strings.TrimSpace(instruction)
+	if instruction == "" {
+		return nil, fmt.Errorf("instruction is required")
+	}
+
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case entity.JobStatusReady2Deploy, entity.JobStatusDeployed, entity.JobStatusFailed:
+		if job.Revision == 0 {
+			return nil, fmt.Errorf("%w: no generated files yet", ErrJobNotRefinable)
+		}
+	default:
+		return nil, fmt.Errorf("%w: status %s", ErrJobNotRefinable, job.Status)
+	}
+
+	if err := u.budget.Check(ctx, job.Owner); err != nil {
+		if !errors.Is(err, ErrBudgetExceeded) || u.budget.Policy() == entity.BudgetPolicyReject {
+			return nil, err
+		}
+	}
+
+	job.Conversation = append(job.Conversation, entity.ChatMessage{
+		Role:      entity.ChatRoleUser,
+		Content:   instruction,
+		CreatedAt: time.Now(),
+	})
+	job.UpdateStatus(entity.JobStatusRefining)
+	if err := u.jobsRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	return job, nil
+}
+
+func (u *JobService) AnswerQuestions(ctx context.Context, jobID string, answers map[string]string) (*entity.Job, error) {
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != entity.JobStatusAwaitingInput {
+		return nil, fmt.Errorf("%w: status %s", ErrJobNotAwaitingInput, job.Status)
+	}
+
+	known := make(map[string]bool, len(job.Questions))
+	var missing []string
+	for i := range job.Questions {
+		q := &job.Questions[i]
+		known[q.ID] = true
+		if answer := strings.TrimSpace(answers[q.ID]); answer != "" {
+			q.Answer = answer
+		}
+		// без ответа берётся предложенное значение; если его нет — вопрос обязателен
+		if q.Answer == "" && q.Default == "" {
+			missing = append(missing, q.ID)
+		}
+	}
+	var unknown []string
+	for id := range answers {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: unknown questions %s", ErrInvalidAnswers, strings.Join(unknown, ", "))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: answers required for %s", ErrInvalidAnswers, strings.Join(missing, ", "))
+	}
+
+	job.Clarified = true
+	job.UpdateStatus(entity.JobStatusPending)
+	if err := u.jobsRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	return job, nil
+}
+
+func (u *JobService) UpdateSpec(ctx context.Context, jobID string, spec *entity.InfraSpec, regenerate bool) (*entity.Job, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("spec is required")
+	}
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case entity.JobStatusRunning, entity.JobStatusRefining, entity.JobStatusDeploying, entity.JobStatusCanceled:
+		return nil, fmt.Errorf("%w: status %s", ErrJobBusy, job.Status)
+	case entity.JobStatusDeployed:
+		if !regenerate {
+			return nil, ErrRegenerateRequired
+		}
+	}
+
+	spec.Cloud = strings.ToLower(strings.TrimSpace(spec.Cloud))
+	job.Spec = spec
+	job.TwoPhase = true
+	// задача, ждущая ответов, дождётся их и сгенерирует файлы уже по новой спецификации
+	if job.Status != entity.JobStatusAwaitingInput && job.Status != entity.JobStatusQueued {
+		job.UpdateStatus(entity.JobStatusPending)
+	}
+	if err := u.jobsRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	return job, nil
+}
+
+func (u *JobService) ActivateRevision(ctx context.Context, jobID string, revision int) (*entity.Job, error) {
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case entity.JobStatusReady2Deploy, entity.JobStatusDeployed, entity.JobStatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: status %s", ErrJobBusy, job.Status)
+	}
+
+	files, err := u.configRepo.GetFilesByRevision(ctx, jobID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("get files of revision %d: %w", revision, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+	}
+	report, err := u.reports.Get(ctx, jobID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("get validation report of revision %d: %w", revision, err)
+	}
+	if err := u.configFileRepo.ReplaceFiles(ctx, files, jobID); err != nil {
+		return nil, fmt.Errorf("write files of revision %d: %w", revision, err)
+	}
+
+	job.Revision = revision
+	for i := range job.Alternatives {
+		job.Alternatives[i].Selected = job.Alternatives[i].Revision == revision
+	}
+	// без отчёта (ручная правка, старая ревизия) результаты проверок неизвестны
+	job.StaticPassed, job.SandboxPassed, job.Suppressed = nil, nil, nil
+	if report != nil {
+		job.StaticPassed = boolPtr(report.Passed)
+		job.SandboxPassed = report.SandboxPassed
+		job.Suppressed = report.Suppressed
+	}
+	job.RequiredInputs = providedInputs(ctx, u.configFileRepo, jobID, validator.RequiredInputs(files))
+	job.Conversation = append(job.Conversation, entity.ChatMessage{
+		Role:      entity.ChatRoleAssistant,
+		Content:   fmt.Sprintf("Switched to revision %d.", revision),
+		Revision:  revision,
+		CreatedAt: time.Now(),
+	})
+	// файлы поменялись — прежний деплой им уже не соответствует
+	job.UpdateStatus(entity.JobStatusReady2Deploy)
+	if err := u.jobsRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	return job, nil
+}
+
+func (u *JobService) RateJob(ctx context.Context, jobID string, rating int) (*entity.Job, error) {
+	if rating < 1 || rating > 5 {
+		return nil, ErrInvalidRating
+	}
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	job.Rating = rating
+	if err := u.jobsRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	return job, nil
+}
+
+func (u *JobService) EditFiles(ctx context.Context, jobID string, edits []entity.ConfigFile) (*entity.Job, error) {
+	if len(edits) == 0 {
+		return nil, fmt.Errorf("at least one file is required")
+	}
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case entity.JobStatusReady2Deploy, entity.JobStatusDeployed, entity.JobStatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: status %s", ErrJobBusy, job.Status)
+	}
+
+	current, err := u.configRepo.GetFilesByRevision(ctx, jobID, job.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("get files of revision %d: %w", job.Revision, err)
+	}
+	revision := job.NextRevision()
+
+	byName := make(map[string]*entity.ConfigFile, len(current))
+	var order []string
+	for _, f := range current {
+		byName[f.Name] = f
+		order = append(order, f.Name)
+	}
+	var names []string
+	for _, e := range edits {
+		name := strings.TrimSpace(e.Name)
+		if name == "" || strings.Contains(name, "/") || strings.Contains(name, "..") {
+			return nil, fmt.Errorf("invalid file name %q", e.Name)
+		}
+		names = append(names, name)
+		if e.Content == "" {
+			delete(byName, name)
+			continue
+		}
+		fileType := e.Type
+		if existing, ok := byName[name]; ok && fileType == "" {
+			fileType = existing.Type
+		}
+		if fileType == "" {
+			fileType = job.Target
+		}
+		if _, ok := byName[name]; !ok {
+			order = append(order, name)
+		}
+		byName[name] = &entity.ConfigFile{Name: name, Content: e.Content, Type: fileType}
+	}
+
+	var files []*entity.ConfigFile
+	for _, name := range order {
+		f, ok := byName[name]
+		if !ok {
+			continue
+		}
+		files = append(files, &entity.ConfigFile{JobID: jobID, Revision: revision, Name: f.Name, Content: f.Content, Type: f.Type})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("edit would remove every file")
+	}
+
+	// правка проверяется как любая ревизия: без отчёта задача не попадёт в датасет и не пройдёт гейты
+	res := u.checker.Check(ctx, job, files, nil, revisionDir(u.configFileRepo.GetBasePath(), jobID, revision))
+	job.HumanEdited = true
+	if err := u.commitRevision(ctx, job, revision, files, res, "Edited manually: "+strings.Join(names, ", ")+"."); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// commitRevision сохраняет файлы ревизии, полученной без генерации, вместе с результатом
+// их проверки res и делает её текущей.
+func (u *JobService) commitRevision(
+	ctx context.Context,
+	job *entity.Job,
+	revision int,
+	files []*entity.ConfigFile,
+	res *validator.AnalysisResult,
+	message string,
+) error {
+	markFilesWithErrors(files, res.Errors)
+	if err := u.configRepo.SaveFiles(ctx, files); err != nil {
+		return fmt.Errorf("save files of revision %d: %w", revision, err)
+	}
+	if err := u.configFileRepo.ReplaceFiles(ctx, files, job.ID); err != nil {
+		return fmt.Errorf("write files of revision %d: %w", revision, err)
+	}
+
+	job.Revision = revision
+	if err := u.reports.Save(ctx, revisionReport(job.ID, revision, files, res)); err != nil {
+		return fmt.Errorf("save validation report of revision %d: %w", revision, err)
+	}
+	job.StaticPassed = boolPtr(res.Passed)
+	// песочница для ревизий без генерации не запускается
+	job.SandboxPassed = nil
+	job.Suppressed = suppressedFindings(res)
+	job.RequiredInputs = providedInputs(ctx, u.configFileRepo, job.ID, validator.RequiredInputs(files))
+	job.Conversation = append(job.Conversation, entity.ChatMessage{
+		Role:      entity.ChatRoleUser,
+		Content:   message,
+		Revision:  revision,
+		CreatedAt: time.Now(),
+	})
+	job.UpdateStatus(entity.JobStatusReady2Deploy)
+	if err := u.jobsRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("update job: %w", err)
+	}
+	return nil
+}
+
+func (u *JobService) Autofix(ctx context.Context, jobID string, rules []string, dryRun bool) (*AutofixOutcome, error) {
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case entity.JobStatusReady2Deploy, entity.JobStatusDeployed, entity.JobStatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: status %s", ErrJobBusy, job.Status)
+	}
+
+	// исправляются замечания последней проверки текущей ревизии
+	report, err := u.reports.Get(ctx, jobID, job.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("get validation report: %w", err)
+	}
+	if report == nil {
+		return nil, fmt.Errorf("%w: job %s, revision %d", ErrValidationReportNotFound, jobID, job.Revision)
+	}
+	var findings []entity.ValidationConfigError
+	for _, f := range report.Files {
+		findings = append(findings, f.Findings...)
+	}
+
+	current, err := u.configRepo.GetFilesByRevision(ctx, jobID, job.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("get files of revision %d: %w", job.Revision, err)
+	}
+	fixed, err := u.checker.staticVal.Autofix(current, findings, rules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAutofix, err)
+	}
+
+	outcome := &AutofixOutcome{Job: job, Fixes: fixed.Fixes, Skipped: fixed.Skipped, Diff: fixed.Diff}
+	if dryRun || fixed.Diff == "" {
+		return outcome, nil
+	}
+
+	revision := job.NextRevision()
+	files := make([]*entity.ConfigFile, 0, len(fixed.Files))
+	for _, f := range fixed.Files {
+		files = append(files, &entity.ConfigFile{JobID: jobID, Revision: revision, Name: f.Name, Content: f.Content, Type: f.Type})
+	}
+	// новая ревизия проверяется так же, как сгенерированная: иначе у неё нет отчёта
+	// и повторное автоисправление невозможно
+	formatErrors := validator.FormatFiles(files)
+	res := u.checker.Check(ctx, job, files, formatErrors, revisionDir(u.configFileRepo.GetBasePath(), jobID, revision))
+	var ruleIDs []string
+	for _, f := range fixed.Fixes {
+		if !slices.Contains(ruleIDs, f.RuleID) {
+			ruleIDs = append(ruleIDs, f.RuleID)
+		}
+	}
+	sort.Strings(ruleIDs)
+	message := fmt.Sprintf("Autofix: %d fix(es) for %s.", len(fixed.Fixes), strings.Join(ruleIDs, ", "))
+	job.RepairRounds++
+	if err := u.commitRevision(ctx, job, revision, files, res, message); err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
+
+func (u *JobService) SetInputs(ctx context.Context, jobID string, values map[string]string) (*entity.Job, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: no values", ErrInvalidInputs)
+	}
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case entity.JobStatusReady2Deploy, entity.JobStatusDeployed, entity.JobStatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: status %s", ErrJobBusy, job.Status)
+	}
+
+	required := make(map[string]*entity.DeployInput, len(job.RequiredInputs))
+	for i := range job.RequiredInputs {
+		required[job.RequiredInputs[i].Name] = &job.RequiredInputs[i]
+	}
+	for name, value := range values {
+		in, ok := required[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not a required input", ErrInvalidInputs, name)
+		}
+		if in.Unsatisfiable {
+			return nil, fmt.Errorf("%w: %s cannot be supplied; fix the placeholder in %s:%d", ErrInvalidInputs, name, in.File, in.Line)
+		}
+		if strings.TrimSpace(value) == "" || strings.Contains(value, entity.SecretPlaceholder) {
+			return nil, fmt.Errorf("%w: value of %s is empty", ErrInvalidInputs, name)
+		}
+	}
+
+	if err := u.configFileRepo.SaveInputs(ctx, jobID, values); err != nil {
+		return nil, fmt.Errorf("save inputs: %w", err)
+	}
+	job.RequiredInputs = providedInputs(ctx, u.configFileRepo, jobID, job.RequiredInputs)
+	if err := u.jobsRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	return job, nil
+}
+
+func (u *JobService) StaticReport(ctx context.Context, jobID string, reporter validator.Reporter) ([]byte, error) {
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.StaticPassed == nil {
+		// анализ ещё не выполнялся — на диске отчёт о прежних файлах
+		return nil, fmt.Errorf("%w: job %s has not been analysed", ErrReportNotFound, jobID)
+	}
+
+	// отчёт активной ревизии: после ActivateRevision это отчёт выбранного кандидата
+	dir := filepath.Join(revisionDir(u.configFileRepo.GetBasePath(), jobID, job.Revision), validator.ReportsDir)
+	data, err := os.ReadFile(filepath.Join(dir, reporter.FileName()))
+	if err == nil {
+		return data, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read %s report: %w", reporter.Format(), err)
+	}
+	return nil, fmt.Errorf("%w: job %s, format %s", ErrReportNotFound, jobID, reporter.Format())
+}
+
+func (u *JobService) ValidationReport(ctx context.Context, jobID string, revision int) (*entity.ValidationReport, error) {
+	job, err := u.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if revision == 0 {
+		revision = job.Revision
+	}
+	// кандидаты best-of-N нумеруются после текущей ревизии — неизвестная ревизия просто не имеет отчёта
+	if revision <= 0 {
+		return nil, fmt.Errorf("%w: job %s has no revision %d", ErrRevisionNotFound, jobID, revision)
+	}
+
+	report, err := u.reports.Get(ctx, jobID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("get validation report: %w", err)
+	}
+	if report == nil {
+		return nil, fmt.Errorf("%w: job %s, revision %d", ErrValidationReportNotFound, jobID, revision)
+	}
+	return report, nil
+}
+
+// providedInputs отмечает входные значения, уже переданные для задачи.
+func providedInputs(ctx context.Context, repo filesystem.FileRepository, jobID string, inputs []entity.DeployInput) []entity.DeployInput {
+	if len(inputs) == 0 {
+		return nil
+	}
+	// при ошибке чтения значения считаются непереданными — деплой просто не начнётся
+	provided, _ := repo.InputNames(ctx, jobID)
+	marked := make([]entity.DeployInput, len(inputs))
+	for i, in := range inputs {
+		in.Provided = !in.Unsatisfiable && provided[in.Name]
+		marked[i] = in
+	}
+	return marked
+}
+
 func repositoryNotFoundError(id string) error {
-	return fmt.Errorf("job %s not found", id)
+	return fmt.Errorf("%w: %s", ErrJobNotFound, id)
 }
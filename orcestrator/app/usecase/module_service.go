@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+type ModuleCatalogUsecase interface {
+	// Reload перечитывает каталог и перестраивает индекс; возвращает число модулей.
+	Reload(ctx context.Context) (int, error)
+	List() []*entity.Module
+	// Search ищет модули по тексту; limit <= 0 — значение по умолчанию.
+	Search(query string, limit int) []entity.ModuleMatch
+	// Context подбирает модули для описания задачи и возвращает текст для промпта
+	// вместе со списком подставленных модулей; пустой текст — подходящих модулей нет.
+	Context(query string) (string, []entity.InjectedSnippet)
+}
+
+var _ ModuleCatalogUsecase = (*ModuleCatalogService)(nil)
+
+// BM25: насыщение частоты термина и нормализация по длине документа.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// moduleStopWords — слишком общие слова, которые есть почти в любом описании.
+var moduleStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "for": true, "to": true, "in": true,
+	"with": true, "on": true, "by": true, "is": true, "be": true, "or": true, "use": true, "this": true,
+	"module": true, "terraform": true, "create": true, "creates": true, "name": true, "id": true,
+}
+
+// ModuleCatalogService держит лексический индекс (BM25) каталога модулей в памяти.
+type ModuleCatalogService struct {
+	repo     repository.ModuleCatalogRepository
+	topK     int
+	minScore float64
+
+	mu      sync.RWMutex
+	modules []*entity.Module
+	docs    []map[string]float64 // модуль -> взвешенные частоты терминов
+	lengths []float64
+	avgLen  float64
+	df      map[string]int
+}
+
+// NewModuleCatalogService: repo == nil — каталог не настроен, поиск всегда пустой.
+func NewModuleCatalogService(repo repository.ModuleCatalogRepository, topK int, minScore float64) *ModuleCatalogService {
+	if topK <= 0 {
+		topK = 3
+	}
+	return &ModuleCatalogService{repo: repo, topK: topK, minScore: minScore, df: map[string]int{}}
+}
+
+func (s *ModuleCatalogService) Reload(ctx context.Context) (int, error) {
+	if s.repo == nil {
+		return 0, nil
+	}
+	modules, err := s.repo.Load(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("load module catalog: %w", err)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+
+	docs := make([]map[string]float64, len(modules))
+	lengths := make([]float64, len(modules))
+	df := map[string]int{}
+	var total float64
+	for i, m := range modules {
+		docs[i] = moduleTerms(m)
+		for term, tf := range docs[i] {
+			df[term]++
+			lengths[i] += tf
+		}
+		total += lengths[i]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modules, s.docs, s.lengths, s.df = modules, docs, lengths, df
+	s.avgLen = 0
+	if len(modules) > 0 {
+		s.avgLen = total / float64(len(modules))
+	}
+	return len(modules), nil
+}
+
+func (s *ModuleCatalogService) List() []*entity.Module {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*entity.Module(nil), s.modules...)
+}
+
+func (s *ModuleCatalogService) Search(query string, limit int) []entity.ModuleMatch {
+	if limit <= 0 {
+		limit = s.topK
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.modules) == 0 {
+		return nil
+	}
+
+	terms := map[string]bool{}
+	for _, t := range tokenize(query) {
+		terms[t] = true
+	}
+
+	n := float64(len(s.modules))
+	var matches []entity.ModuleMatch
+	for i, doc := range s.docs {
+		var score float64
+		for term := range terms {
+			tf := doc[term]
+			if tf == 0 {
+				continue
+			}
+			df := float64(s.df[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*s.lengths[i]/s.avgLen
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		if score > 0 && score >= s.minScore {
+			matches = append(matches, entity.ModuleMatch{Module: s.modules[i], Score: math.Round(score*100) / 100})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func (s *ModuleCatalogService) Context(query string) (string, []entity.InjectedSnippet) {
+	matches := s.Search(query, s.topK)
+	if len(matches) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Approved internal modules. Whenever one of them covers part of the request, call it with a module block ")
+	b.WriteString("using exactly the given source instead of writing the equivalent raw resources, and set all its required inputs.\n")
+
+	snippets := make([]entity.InjectedSnippet, 0, len(matches))
+	for _, m := range matches {
+		writeModule(&b, m.Module)
+		snippets = append(snippets, entity.InjectedSnippet{Module: m.Module.Name, Source: m.Module.Source, Score: m.Score})
+	}
+	return b.String(), snippets
+}
+
+func writeModule(b *strings.Builder, m *entity.Module) {
+	fmt.Fprintf(b, "\nModule %q (source = %q)", m.Name, m.Source)
+	if m.Description != "" {
+		fmt.Fprintf(b, ": %s", m.Description)
+	}
+	b.WriteString("\n")
+	if len(m.Inputs) > 0 {
+		b.WriteString("Inputs:\n")
+		for _, in := range m.Inputs {
+			fmt.Fprintf(b, "- %s", in.Name)
+			var attrs []string
+			if in.Type != "" {
+				attrs = append(attrs, in.Type)
+			}
+			if in.Required {
+				attrs = append(attrs, "required")
+			}
+			if len(attrs) > 0 {
+				fmt.Fprintf(b, " (%s)", strings.Join(attrs, ", "))
+			}
+			if in.Description != "" {
+				fmt.Fprintf(b, ": %s", in.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(m.Outputs) > 0 {
+		fmt.Fprintf(b, "Outputs: %s\n", strings.Join(m.Outputs, ", "))
+	}
+	if m.Example != "" {
+		// пример без блока кода, чтобы модель не приняла его за файл ответа
+		b.WriteString("Example usage:\n")
+		for _, line := range strings.Split(m.Example, "\n") {
+			b.WriteString("    " + line + "\n")
+		}
+	}
+}
+
+// moduleTerms — термины модуля; имя и source весят больше описания и входов.
+func moduleTerms(m *entity.Module) map[string]float64 {
+	terms := map[string]float64{}
+	add := func(text string, weight float64) {
+		for _, t := range tokenize(text) {
+			terms[t] += weight
+		}
+	}
+	add(m.Name, 3)
+	add(m.Source, 1)
+	add(m.Description, 2)
+	for _, in := range m.Inputs {
+		add(in.Name, 1)
+		add(in.Description, 1)
+	}
+	add(strings.Join(m.Outputs, " "), 1)
+	return terms
+}
+
+// tokenize разбивает текст на слова в нижнем регистре (snake_case и пути тоже делятся),
+// выкидывает стоп-слова и приводит множественное число к единственному.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for _, w := range words {
+		if len(w) < 2 || moduleStopWords[w] {
+			continue
+		}
+		tokens = append(tokens, singular(w))
+	}
+	return tokens
+}
+
+func singular(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+var (
+	ErrPromptNotFound = errors.New("prompt not found")
+	// ErrPromptPinned — версию нельзя удалить, пока она закреплена в конфиге как промпт по умолчанию.
+	ErrPromptPinned = errors.New("prompt version is pinned as a default")
+)
+
+type PromptUsecase interface {
+	// Seed добавляет встроенные промпты, которых ещё нет в библиотеке.
+	Seed(ctx context.Context) error
+	// Create сохраняет текст как новую версию промпта id (первую, если промпта ещё нет).
+	Create(ctx context.Context, prompt entity.Prompt) (*entity.Prompt, error)
+	Get(ctx context.Context, id string, version int) (*entity.Prompt, error)
+	List(ctx context.Context) ([]*entity.Prompt, error)
+	ListVersions(ctx context.Context, id string) ([]*entity.Prompt, error)
+	Delete(ctx context.Context, id string, version int) error
+	Defaults() map[string]entity.PromptRef
+	// Resolve выбирает промпт для задачи: явная ссылка или закреплённый промпт цели.
+	Resolve(ctx context.Context, target string, ref entity.PromptRef) (*entity.Prompt, error)
+}
+
+var _ PromptUsecase = (*PromptService)(nil)
+
+type PromptService struct {
+	repo     repository.PromptRepository
+	defaults map[string]entity.PromptRef // target -> промпт по умолчанию
+}
+
+func NewPromptService(repo repository.PromptRepository, defaults map[string]entity.PromptRef) *PromptService {
+	if defaults == nil {
+		defaults = map[string]entity.PromptRef{}
+	}
+	return &PromptService{repo: repo, defaults: defaults}
+}
+
+func (s *PromptService) Seed(ctx context.Context) error {
+	for _, builtin := range entity.BuiltinPrompts {
+		existing, err := s.repo.Get(ctx, builtin.ID, builtin.Version)
+		if err != nil {
+			return fmt.Errorf("get prompt %s@%d: %w", builtin.ID, builtin.Version, err)
+		}
+		if existing != nil {
+			continue
+		}
+		p := builtin
+		if err := s.repo.Create(ctx, &p); err != nil {
+			return fmt.Errorf("seed prompt %s@%d: %w", builtin.ID, builtin.Version, err)
+		}
+	}
+	return nil
+}
+
+func (s *PromptService) Create(ctx context.Context, prompt entity.Prompt) (*entity.Prompt, error) {
+	prompt.ID = strings.TrimSpace(prompt.ID)
+	if prompt.ID == "" || strings.Contains(prompt.ID, "@") {
+		return nil, fmt.Errorf("invalid prompt id %q", prompt.ID)
+	}
+	if strings.TrimSpace(prompt.Text) == "" {
+		return nil, fmt.Errorf("prompt text is required")
+	}
+
+	latest, err := s.repo.Latest(ctx, prompt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get latest prompt %s: %w", prompt.ID, err)
+	}
+	prompt.Version = 1
+	prompt.CreatedAt = time.Now()
+	if latest != nil {
+		prompt.Version = latest.Version + 1
+		if prompt.Target == "" {
+			prompt.Target = latest.Target
+		}
+	}
+	if prompt.Target == "" {
+		return nil, fmt.Errorf("prompt target is required")
+	}
+
+	if err := s.repo.Create(ctx, &prompt); err != nil {
+		return nil, fmt.Errorf("create prompt %s@%d: %w", prompt.ID, prompt.Version, err)
+	}
+	return &prompt, nil
+}
+
+func (s *PromptService) Get(ctx context.Context, id string, version int) (*entity.Prompt, error) {
+	var (
+		prompt *entity.Prompt
+		err    error
+	)
+	if version == 0 {
+		prompt, err = s.repo.Latest(ctx, id)
+	} else {
+		prompt, err = s.repo.Get(ctx, id, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get prompt %s: %w", entity.PromptRef{ID: id, Version: version}, err)
+	}
+	if prompt == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, entity.PromptRef{ID: id, Version: version})
+	}
+	return prompt, nil
+}
+
+func (s *PromptService) List(ctx context.Context) ([]*entity.Prompt, error) {
+	prompts, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list prompts: %w", err)
+	}
+	return prompts, nil
+}
+
+func (s *PromptService) ListVersions(ctx context.Context, id string) ([]*entity.Prompt, error) {
+	prompts, err := s.repo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt versions %s: %w", id, err)
+	}
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, id)
+	}
+	return prompts, nil
+}
+
+func (s *PromptService) Delete(ctx context.Context, id string, version int) error {
+	existing, err := s.repo.Get(ctx, id, version)
+	if err != nil {
+		return fmt.Errorf("get prompt %s: %w", entity.PromptRef{ID: id, Version: version}, err)
+	}
+	if existing == nil {
+		return fmt.Errorf("%w: %s", ErrPromptNotFound, entity.PromptRef{ID: id, Version: version})
+	}
+
+	latest, err := s.repo.Latest(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get latest prompt %s: %w", id, err)
+	}
+
+	var targets []string
+	for target, ref := range s.defaults {
+		if ref.ID != id {
+			continue
+		}
+		// закреплённая без версии ссылка указывает на последнюю версию
+		if ref.Version == version || (ref.Version == 0 && latest != nil && latest.Version == version) {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) > 0 {
+		sort.Strings(targets)
+		return fmt.Errorf("%w for %s", ErrPromptPinned, strings.Join(targets, ", "))
+	}
+
+	if err := s.repo.Delete(ctx, id, version); err != nil {
+		return fmt.Errorf("delete prompt %s@%d: %w", id, version, err)
+	}
+	return nil
+}
+
+func (s *PromptService) Defaults() map[string]entity.PromptRef {
+	return s.defaults
+}
+
+func (s *PromptService) Resolve(ctx context.Context, target string, ref entity.PromptRef) (*entity.Prompt, error) {
+	if ref.ID == "" {
+		pinned, ok := s.defaults[target]
+		if !ok {
+			return nil, fmt.Errorf("no default prompt configured for target %q", target)
+		}
+		ref = pinned
+	}
+	return s.Get(ctx, ref.ID, ref.Version)
+}
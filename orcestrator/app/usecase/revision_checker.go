@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/infrastructure/validator"
+)
+
+// RevisionChecker — статические проверки файлов ревизии: анализатор, соответствие спецификации
+// и политики проекта. Общий для генерации и ревизий, полученных без неё (автоисправление).
+type RevisionChecker struct {
+	staticVal *validator.TerraformAnalyzer
+	specVal   *validator.SpecAnalyzer
+	policies  *validator.PolicyEngine
+	logger    *slog.Logger
+}
+
+func NewRevisionChecker(staticVal *validator.TerraformAnalyzer, policies *validator.PolicyEngine, logger *slog.Logger) *RevisionChecker {
+	return &RevisionChecker{
+		staticVal: staticVal,
+		specVal:   validator.NewSpecAnalyzer(),
+		policies:  policies,
+		logger:    logger,
+	}
+}
+
+// Check проверяет файлы ревизии и пишет отчёты анализатора в workDir. extra — замечания,
+// найденные до проверки (разбор ответа модели, форматирование): подавления действуют и на них.
+func (c *RevisionChecker) Check(
+	ctx context.Context,
+	job *entity.Job,
+	files []*entity.ConfigFile,
+	extra []*entity.ValidationConfigError,
+	workDir string,
+) *validator.AnalysisResult {
+	// расхождения со спецификацией задачи
+	specErrors := c.specVal.Check(files, job.Spec)
+	if len(specErrors) > 0 {
+		c.logger.Warn("files do not match spec", "job_id", job.ID, "count", len(specErrors))
+	}
+	// политики Rego проекта
+	policyErrors, err := c.policies.Evaluate(ctx, files, job.Project)
+	if err != nil {
+		// непроверенная ревизия не должна считаться прошедшей политики
+		c.logger.Error("policy evaluation failed", "job_id", job.ID, "err", err)
+		policyErrors = append(policyErrors, &entity.ValidationConfigError{
+			RuleID:   validator.RulePolicy,
+			Severity: entity.SeverityError,
+			Message:  "policy evaluation failed: " + err.Error(),
+		})
+	}
+	if len(policyErrors) > 0 {
+		c.logger.Warn("policy violations", "job_id", job.ID, "count", len(policyErrors))
+	}
+
+	// подавления и настройки правил применяются анализатором ко всем замечаниям сразу
+	var found []*entity.ValidationConfigError
+	for _, group := range [][]*entity.ValidationConfigError{extra, specErrors, policyErrors} {
+		found = append(found, group...)
+	}
+	res, err := c.staticVal.AnalyzeWithFindings(files, found, workDir, job.Project)
+	if err != nil {
+		c.logger.Error("static validator error", "job_id", job.ID, "err", err)
+		res = &validator.AnalysisResult{Errors: c.staticVal.Grade(job.Project, found)}
+	}
+	return res
+}
+
+// revisionDir — каталог ревизии задачи, куда анализатор пишет её отчёты.
+func revisionDir(basePath, jobID string, revision int) string {
+	return filepath.Join(basePath, jobID, "revisions", strconv.Itoa(revision))
+}
+
+// revisionReport — отчёт валидации ревизии по результату статических проверок;
+// результаты песочницы и security-проверок дописывает вызывающий.
+func revisionReport(jobID string, revision int, files []*entity.ConfigFile, res *validator.AnalysisResult) *entity.ValidationReport {
+	report := entity.NewValidationReport(jobID, revision, fileNames(files), res.Errors)
+	report.Passed = res.Passed
+	report.Suppressed = suppressedFindings(res)
+	return report
+}
+
+func suppressedFindings(res *validator.AnalysisResult) []entity.SuppressedFinding {
+	var out []entity.SuppressedFinding
+	for _, sf := range res.Suppressed {
+		out = append(out, *sf)
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// DefaultOwner используется для задач, у которых не указан владелец/команда.
+const DefaultOwner = "default"
+
+type BudgetPolicy string
+
+const (
+	// BudgetPolicyReject — при исчерпании бюджета создание задачи отклоняется.
+	BudgetPolicyReject BudgetPolicy = "reject"
+	// BudgetPolicyQueue — задача создаётся, но ждёт в очереди до следующего окна.
+	BudgetPolicyQueue BudgetPolicy = "queue"
+)
+
+// TokenUsage — расход токенов, о котором сообщил LLM провайдер.
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// UsageRecord — запись в журнале расхода LLM по владельцу.
+type UsageRecord struct {
+	Owner     string     `json:"owner"`
+	JobID     string     `json:"job_id"`
+	Model     string     `json:"model"`
+	Usage     TokenUsage `json:"usage"`
+	Cost      float64    `json:"cost"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UsageTotals — агрегированный расход за окно.
+type UsageTotals struct {
+	Tokens int64   `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+// Budget — лимиты владельца. Нулевое значение лимита означает «без ограничений».
+type Budget struct {
+	DailyTokens   int64   `json:"daily_tokens,omitempty" yaml:"daily_tokens"`
+	MonthlyTokens int64   `json:"monthly_tokens,omitempty" yaml:"monthly_tokens"`
+	DailyCost     float64 `json:"daily_cost,omitempty" yaml:"daily_cost"`
+	MonthlyCost   float64 `json:"monthly_cost,omitempty" yaml:"monthly_cost"`
+}
+
+type BudgetWindow struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	TokensUsed  int64     `json:"tokens_used"`
+	TokensLimit int64     `json:"tokens_limit,omitempty"`
+	CostUsed    float64   `json:"cost_used"`
+	CostLimit   float64   `json:"cost_limit,omitempty"`
+	Exhausted   bool      `json:"exhausted"`
+}
+
+// BudgetState — текущее состояние бюджета владельца, отдаётся через API.
+type BudgetState struct {
+	Owner     string       `json:"owner"`
+	Policy    BudgetPolicy `json:"policy"`
+	Daily     BudgetWindow `json:"daily"`
+	Monthly   BudgetWindow `json:"monthly"`
+	Exhausted bool         `json:"exhausted"`
+}
@@ -1,12 +1,13 @@
 package entity
 
 type ConfigFile struct {
-	JobID    string                 `json:"job_id"`
-	Name     string                 `json:"name"`
-	Content  string                 `json:"content"`
-	Type     string                 `json:"type"` // terraform, kubernetes, ansible;
-	HasError bool                   `json:"has_error"`
-	ErrorMsg *ValidationConfigError `json:"error_msg,omitempty"`
+	JobID    string                  `json:"job_id"`
+	Revision int                     `json:"revision"`
+	Name     string                  `json:"name"`
+	Content  string                  `json:"content"`
+	Type     string                  `json:"type"`      // terraform, kubernetes, ansible;
+	HasError bool                    `json:"has_error"` // есть замечания с серьёзностью error
+	Findings []ValidationConfigError `json:"findings,omitempty"`
 }
 
 type ValidationConfigError struct {
@@ -14,4 +15,23 @@ type ValidationConfigError struct {
 	Message string `json:"message"`
 	Line    int    `json:"line,omitempty"`
 	Column  int    `json:"column,omitempty"`
+	// Правило, которое сработало, и его серьёзность с учётом настроек проекта.
+	RuleID   string   `json:"rule_id,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
 }
+
+// SuppressedFinding — замечание, подавленное комментарием orchestrator:ignore в файле.
+type SuppressedFinding struct {
+	Finding      ValidationConfigError `json:"finding"`
+	Reason       string                `json:"reason"`
+	SuppressedAt int                   `json:"suppressed_at"` // строка комментария подавления
+}
+
+// Severity — серьёзность замечания: только error делает файлы непригодными к деплою.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
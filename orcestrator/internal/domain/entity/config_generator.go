@@ -12,4 +12,10 @@ type GenerateResponse struct {
 	RequestID string        `json:"request_id"`
 	CreatedAt time.Time     `json:"created_at"`
 	Status    string        `json:"status"`
+	Model     string        `json:"model"`
+	Usage     TokenUsage    `json:"usage"`
+	CacheHit  bool          `json:"cache_hit,omitempty"`
+
+	// ParseErrors — проблемы разбора ответа модели (обрезанный вывод, блок без имени файла и т.п.).
+	ParseErrors []*ValidationConfigError `json:"parse_errors,omitempty"`
 }
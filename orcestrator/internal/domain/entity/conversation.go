@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+type ChatRole string
+
+const (
+	ChatRoleUser      ChatRole = "user"
+	ChatRoleAssistant ChatRole = "assistant"
+)
+
+// ChatMessage — реплика диалога по задаче: запрос пользователя или итог генерации.
+type ChatMessage struct {
+	Role      ChatRole  `json:"role"`
+	Content   string    `json:"content"`
+	Revision  int       `json:"revision,omitempty"` // ревизия файлов, к которой относится реплика
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClarifyingQuestion — недостающий параметр, который модель просит уточнить до генерации.
+type ClarifyingQuestion struct {
+	ID       string `json:"id"`
+	Question string `json:"question"`
+	Default  string `json:"default,omitempty"` // предложенное моделью значение, если пользователь не ответил
+	Answer   string `json:"answer,omitempty"`
+}
+
+type ClarifyResponse struct {
+	Questions []ClarifyingQuestion `json:"questions"`
+	Model     string               `json:"model"`
+	Usage     TokenUsage           `json:"usage"`
+}
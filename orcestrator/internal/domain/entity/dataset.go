@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// DatasetFilter отбирает задачи для выгрузки датасета.
+type DatasetFilter struct {
+	Target string
+	// From и To ограничивают дату создания задачи; нулевое значение — без ограничения.
+	From time.Time
+	To   time.Time
+	// MinRating > 0 оставляет только оценённые не ниже порога задачи.
+	MinRating int
+	// IncludeValidated добавляет к развёрнутым задачам прошедшие валидацию, но ещё не развёрнутые.
+	IncludeValidated bool
+	// EditedOnly — только задачи, файлы которых правил человек.
+	EditedOnly bool
+	// WithMetadata добавляет в каждую запись служебное поле metadata (для оценки, не для обучения).
+	WithMetadata bool
+}
+
+// DatasetRecord — строка JSONL в формате chat completion.
+type DatasetRecord struct {
+	Messages []DatasetMessage `json:"messages"`
+	Metadata *DatasetMetadata `json:"metadata,omitempty"`
+}
+
+type DatasetMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type DatasetMetadata struct {
+	JobID       string    `json:"job_id"`
+	Target      string    `json:"target"`
+	Status      JobStatus `json:"status"`
+	Revision    int       `json:"revision"`
+	Rating      int       `json:"rating,omitempty"`
+	HumanEdited bool      `json:"human_edited"`
+	Prompt      string    `json:"prompt,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
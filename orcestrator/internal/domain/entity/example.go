@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+// ExampleSource — почему задача попала в базу примеров.
+type ExampleSource string
+
+const (
+	ExampleSourceValidated ExampleSource = "validated" // прошла все проверки без замечаний
+	ExampleSourceDeployed  ExampleSource = "deployed"  // успешно развёрнута
+)
+
+// Example — пара «описание → файлы» из успешной задачи для few-shot подсказок.
+// ID совпадает с ID задачи: у задачи не больше одного примера, последняя удачная ревизия его заменяет.
+type Example struct {
+	ID          string        `json:"id"`
+	Target      string        `json:"target"`
+	Description string        `json:"description"`
+	Files       []ExampleFile `json:"files"`
+	Source      ExampleSource `json:"source"`
+	Revision    int           `json:"revision"`
+	// Pinned — пример всегда подставляется для своего target; Excluded — никогда.
+	Pinned   bool `json:"pinned"`
+	Excluded bool `json:"excluded"`
+	// Embedding описания и модель, которой он посчитан; пусто — используется лексический поиск.
+	Embedding      []float64 `json:"-"`
+	EmbeddingModel string    `json:"embedding_model,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type ExampleFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// ExampleMatch — пример и его близость к описанию задачи (косинус, 0..1).
+type ExampleMatch struct {
+	Example    *Example `json:"example"`
+	Similarity float64  `json:"similarity"`
+}
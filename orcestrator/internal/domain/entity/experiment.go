@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Experiment делит задачи одного target между вариантами промпта/модели в заданных пропорциях.
+type Experiment struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Target    string              `json:"target"`
+	Active    bool                `json:"active"`
+	Variants  []ExperimentVariant `json:"variants"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type ExperimentVariant struct {
+	Name          string `json:"name"`
+	PromptID      string `json:"prompt_id"`
+	PromptVersion int    `json:"prompt_version,omitempty"`
+	Model         string `json:"model,omitempty"` // пусто — модель по умолчанию
+	Weight        int    `json:"weight"`          // доля трафика в процентах
+}
+
+// VariantOutcome — итоги варианта по задачам, которые в него попали.
+// Доли считаются только по задачам, дошедшим до соответствующего этапа; nil — данных нет.
+type VariantOutcome struct {
+	Variant           string   `json:"variant"`
+	Jobs              int      `json:"jobs"`
+	StaticPassRate    *float64 `json:"static_pass_rate"`
+	SandboxPassRate   *float64 `json:"sandbox_pass_rate"`
+	AvgRepairRounds   float64  `json:"avg_repair_rounds"` // доработок файлов после генерации на задачу
+	AvgTokens         float64  `json:"avg_tokens"`
+	DeploySuccessRate *float64 `json:"deploy_success_rate"`
+}
+
+type ExperimentSummary struct {
+	Experiment *Experiment       `json:"experiment"`
+	Variants   []*VariantOutcome `json:"variants"`
+}
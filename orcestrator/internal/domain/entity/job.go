@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,22 +11,110 @@ import (
 type JobStatus string
 
 const (
-	JobStatusPending      JobStatus = "pending"
-	JobStatusRunning      JobStatus = "running"
-	JobStatusFailed       JobStatus = "failed"
-	JobStatusReady2Deploy JobStatus = "ready_to_deploy"
-	JobStatusCanceled     JobStatus = "canceled"
-	JobStatusDeploying    JobStatus = "deploying"
-	JobStatusDeployed     JobStatus = "deployed"
+	JobStatusPending       JobStatus = "pending"
+	JobStatusQueued        JobStatus = "queued"         // ждёт следующего окна бюджета
+	JobStatusRefining      JobStatus = "refining"       // ждёт доработки файлов по инструкции пользователя
+	JobStatusAwaitingInput JobStatus = "awaiting_input" // ждёт ответов на уточняющие вопросы
+	JobStatusRunning       JobStatus = "running"
+	JobStatusFailed        JobStatus = "failed"
+	JobStatusReady2Deploy  JobStatus = "ready_to_deploy"
+	JobStatusCanceled      JobStatus = "canceled"
+	JobStatusDeploying     JobStatus = "deploying"
+	JobStatusDeployed      JobStatus = "deployed"
 )
 
 type Job struct {
-	ID          string    `json:"id" db:"id"`
-	Description string    `json:"description" db:"description"`
-	Target      string    `json:"target" db:"target"` // terraform, kubernetes, ansible
-	Status      JobStatus `json:"status" db:"status"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          string     `json:"id" db:"id"`
+	Description string     `json:"description" db:"description"`
+	Target      string     `json:"target" db:"target"` // terraform, kubernetes, ansible
+	Status      JobStatus  `json:"status" db:"status"`
+	Owner       string     `json:"owner" db:"owner"`               // владелец или команда, к бюджету которой относится задача
+	Project     string     `json:"project,omitempty" db:"project"` // проект: задаёт набор правил статического анализа
+	Usage       TokenUsage `json:"usage" db:"usage"`
+	NoCache     bool       `json:"no_cache" db:"no_cache"`   // не использовать кэш ответов LLM
+	CacheHit    bool       `json:"cache_hit" db:"cache_hit"` // файлы взяты из кэша, а не сгенерированы заново
+	// Промпт, которым сгенерированы файлы. До генерации — запрошенный промпт (пусто — по умолчанию для target).
+	PromptID      string `json:"prompt_id,omitempty" db:"prompt_id"`
+	PromptVersion int    `json:"prompt_version,omitempty" db:"prompt_version"`
+	Model         string `json:"model,omitempty" db:"model"`
+	// Эксперимент и вариант, в который попала задача.
+	ExperimentID string `json:"experiment_id,omitempty" db:"experiment_id"`
+	Variant      string `json:"variant,omitempty" db:"variant"`
+	// Исходы этапов; nil — этап не выполнялся.
+	StaticPassed  *bool  `json:"static_passed,omitempty" db:"static_passed"`
+	SandboxPassed *bool  `json:"sandbox_passed,omitempty" db:"sandbox_passed"`
+	RepairRounds  int    `json:"repair_rounds" db:"repair_rounds"` // доработки файлов после генерации: refine и автоисправление
+	DeployError   string `json:"deploy_error,omitempty" db:"deploy_error"`
+	// Замечания текущей ревизии, подавленные комментариями orchestrator:ignore.
+	Suppressed []SuppressedFinding `json:"suppressed,omitempty" db:"suppressed"`
+	// Значения, без которых деплой невозможен (плейсхолдеры REPLACE_ME в файлах).
+	RequiredInputs []DeployInput `json:"required_inputs,omitempty" db:"required_inputs"`
+	// Уточняющие вопросы перед генерацией: ClarifyFirst включает фазу, Clarified — фаза пройдена.
+	ClarifyFirst bool                 `json:"clarify_first" db:"clarify_first"`
+	Clarified    bool                 `json:"clarified" db:"clarified"`
+	Questions    []ClarifyingQuestion `json:"questions,omitempty" db:"questions"`
+	// Двухфазная генерация: сначала структурированная спецификация, затем код по ней.
+	TwoPhase bool       `json:"two_phase" db:"two_phase"`
+	Spec     *InfraSpec `json:"spec,omitempty" db:"spec"`
+	// Best-of-N: сколько кандидатов сгенерировать (0 или 1 — один) и их итоги.
+	Candidates   int               `json:"candidates" db:"candidates"`
+	Alternatives []CandidateResult `json:"alternatives,omitempty" db:"alternatives"`
+	// Модули каталога, подставленные в контекст генерации.
+	InjectedSnippets []InjectedSnippet `json:"injected_snippets,omitempty" db:"injected_snippets"`
+	// Примеры прошлых задач, подставленные в промпт как few-shot.
+	FewShotExamples []string `json:"few_shot_examples,omitempty" db:"few_shot_examples"`
+	// Оценка результата пользователем (1..5, 0 — не оценено) и признак ручной правки файлов.
+	Rating      int  `json:"rating,omitempty" db:"rating"`
+	HumanEdited bool `json:"human_edited" db:"human_edited"`
+	// Текущая ревизия файлов и история диалога доработок.
+	Revision     int           `json:"revision" db:"revision"`
+	Conversation []ChatMessage `json:"conversation,omitempty" db:"conversation"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// SecretPlaceholder — значение, которое модель оставляет вместо секретов и других
+// неизвестных ей параметров; реальное значение передаётся перед деплоем.
+const SecretPlaceholder = "REPLACE_ME"
+
+// DeployInput — переменная, значение которой нужно передать перед деплоем
+// (в файлах вместо него стоит SecretPlaceholder). Само значение в задаче не хранится.
+type DeployInput struct {
+	Name        string `json:"name"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Description string `json:"description,omitempty"`
+	Sensitive   bool   `json:"sensitive"`
+	Provided    bool   `json:"provided"`
+	// Unsatisfiable — плейсхолдер вне переменной (Name — адрес блока): значение для него
+	// передать нельзя, деплой заблокирован до исправления файлов.
+	Unsatisfiable bool `json:"unsatisfiable,omitempty"`
+}
+
+// MissingInputs — имена обязательных входных значений, которые ещё не переданы.
+func (j *Job) MissingInputs() []string {
+	var missing []string
+	for _, in := range j.RequiredInputs {
+		if !in.Provided {
+			missing = append(missing, in.Name)
+		}
+	}
+	return missing
+}
+
+// CandidateResult — итог валидации одного кандидата; каждый кандидат хранится как отдельная ревизия.
+type CandidateResult struct {
+	Revision       int   `json:"revision"`
+	Score          int   `json:"score"`
+	StaticPassed   bool  `json:"static_passed"`
+	SandboxPassed  *bool `json:"sandbox_passed,omitempty"`
+	SecurityPassed *bool `json:"security_passed,omitempty"`
+	Findings       int   `json:"findings"`
+	Tokens         int64 `json:"tokens"`
+	Selected       bool  `json:"selected"`
+	// Suppressed — подавленные замечания ревизии; в Findings не входят.
+	Suppressed []SuppressedFinding `json:"suppressed,omitempty"`
+	Inputs     []DeployInput       `json:"inputs,omitempty"`
 }
 
 func NewJob(description, target string) *Job {
@@ -33,6 +123,7 @@ func NewJob(description, target string) *Job {
 		Description: description,
 		Target:      target,
 		Status:      JobStatusPending,
+		Owner:       DefaultOwner,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -43,6 +134,42 @@ func (j *Job) UpdateStatus(status JobStatus) {
 	j.UpdatedAt = time.Now()
 }
 
+// NextRevision — номер для новой ревизии: больше текущей и всех альтернативных кандидатов.
+func (j *Job) NextRevision() int {
+	next := j.Revision + 1
+	for _, alt := range j.Alternatives {
+		if alt.Revision >= next {
+			next = alt.Revision + 1
+		}
+	}
+	return next
+}
+
 func (j *Job) IsReadyForDeploy() bool {
 	return j.Status == JobStatusReady2Deploy
 }
+
+// Requirements — описание задачи вместе с ответами на уточняющие вопросы и спецификацией.
+func (j *Job) Requirements() string {
+	if len(j.Questions) == 0 && j.Spec == nil {
+		return j.Description
+	}
+	var b strings.Builder
+	b.WriteString(j.Description)
+	if len(j.Questions) > 0 {
+		b.WriteString("\n\nConfirmed parameters (use exactly these values, do not assume others):\n")
+		for _, q := range j.Questions {
+			answer := q.Answer
+			if answer == "" {
+				answer = q.Default
+			}
+			fmt.Fprintf(&b, "- %s: %s\n", q.Question, answer)
+		}
+	}
+	if j.Spec != nil {
+		b.WriteString("\n\nImplement exactly this specification; it takes precedence over the text above:\n")
+		b.WriteString(j.Spec.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package entity
+
+// Module — одобренный внутренний модуль Terraform из каталога организации.
+type Module struct {
+	Name        string        `json:"name"`
+	Source      string        `json:"source"` // значение source в блоке module, например internal/vpc
+	Description string        `json:"description,omitempty"`
+	Inputs      []ModuleInput `json:"inputs,omitempty"`
+	Outputs     []string      `json:"outputs,omitempty"`
+	Example     string        `json:"example,omitempty"` // пример вызова модуля
+}
+
+type ModuleInput struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// ModuleMatch — модуль, найденный по описанию задачи, и его релевантность.
+type ModuleMatch struct {
+	Module *Module `json:"module"`
+	Score  float64 `json:"score"`
+}
+
+// InjectedSnippet — модуль каталога, добавленный в контекст генерации задачи.
+type InjectedSnippet struct {
+	Module string  `json:"module"`
+	Source string  `json:"source"`
+	Score  float64 `json:"score"`
+}
@@ -1,28 +1,92 @@
 package entity
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prompt — версия промпта. Версии неизменяемы: правка промпта создаёт новую версию,
+// чтобы по записанным на задаче ID и версии можно было воспроизвести генерацию.
 type Prompt struct {
-	ID   string
-	Text string
+	ID          string    `json:"id"`
+	Version     int       `json:"version"`
+	Target      string    `json:"target"` // terraform, kubernetes, ansible
+	Description string    `json:"description,omitempty"`
+	Text        string    `json:"text"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PromptRef — ссылка на промпт вида "terraform@2". Нулевая версия означает последнюю.
+type PromptRef struct {
+	ID      string `json:"id"`
+	Version int    `json:"version,omitempty"`
+}
+
+func ParsePromptRef(s string) (PromptRef, error) {
+	id, version, found := strings.Cut(strings.TrimSpace(s), "@")
+	if id == "" {
+		return PromptRef{}, fmt.Errorf("empty prompt id in %q", s)
+	}
+	ref := PromptRef{ID: id}
+	if found {
+		v, err := strconv.Atoi(version)
+		if err != nil || v < 1 {
+			return PromptRef{}, fmt.Errorf("invalid prompt version in %q", s)
+		}
+		ref.Version = v
+	}
+	return ref, nil
+}
+
+func (r PromptRef) String() string {
+	if r.Version == 0 {
+		return r.ID
+	}
+	return fmt.Sprintf("%s@%d", r.ID, r.Version)
 }
 
 const terraformPrompt = "You are TerraformAI — output only complete, deployable Terraform HCL files inside Markdown code fences.\nRules:\n\n1. Output only fenced code blocks — no prose, comments, or text outside them.\n2. Fence format must be exactly:\n   ```<filename>\n   ...HCL...\n   ```\n   — no spaces, no language tags.\n3. Each file = one fenced block (e.g. main.tf, variables.tf, outputs.tf, iam.tf).\n4. All HCL must be valid and runnable (terraform init && apply) with sensible defaults.\n   - Declare and define all variables.\n   - No undefined references.\n   - Include provider config.\n5. If needed, create IAM/VPC/etc. resources referenced by others.\n6. No helper text or examples outside code fences.\n7. End every block with closing triple backticks.\n8. Use placeholders like \"REPLACE_ME\" for secrets.\n9. Generate only what’s needed for the given request.\n\nExample:\n```main.tf\n# valid HCL here\n```\n```variables.tf\n# valid variables here\n```\n\nNow, for the next user instruction, output the Terraform files exactly as above."
 
+// terraformPromptV3 — terraformPrompt, где секреты объявляются чувствительными переменными.
+const terraformPromptV3 = "You are TerraformAI — output only complete, deployable Terraform HCL files inside Markdown code fences.\nRules:\n\n1. Output only fenced code blocks — no prose, comments, or text outside them.\n2. Fence format must be exactly:\n   ```<filename>\n   ...HCL...\n   ```\n   — no spaces, no language tags.\n3. Each file = one fenced block (e.g. main.tf, variables.tf, outputs.tf, iam.tf).\n4. All HCL must be valid and runnable (terraform init && apply) with sensible defaults.\n   - Declare and define all variables.\n   - No undefined references.\n   - Include provider config.\n5. If needed, create IAM/VPC/etc. resources referenced by others.\n6. No helper text or examples outside code fences.\n7. End every block with closing triple backticks.\n8. For secrets declare a variable with sensitive = true and default = \"REPLACE_ME\"; never put \"REPLACE_ME\" directly into resources.\n9. Generate only what’s needed for the given request.\n\nExample:\n```main.tf\n# valid HCL here\n```\n```variables.tf\n# valid variables here\n```\n\nNow, for the next user instruction, output the Terraform files exactly as above."
+
 var TerraformPrompt = Prompt{
-	ID:   "terraform",
-	Text: terraformPrompt,
+	ID:          "terraform",
+	Version:     1,
+	Target:      "terraform",
+	Description: "Markdown fences, one block per file",
+	Text:        terraformPrompt,
 }
 
 var TerraformPromptV2 = Prompt{
-	ID:   "terraform",
-	Text: "You are TerraformAI, an AI agent that builds and deploys Cloud Infrastructure written in Terraform HCL. Generate a description of the Terraform program you will define, followed by a single Terraform HCL program in response to each of my Instructions. Make sure the configuration is deployable. Create IAM roles as needed. If variables are used, make sure default values are supplied. Be sure to include a valid provider configuration within a valid region. Make sure there are no undeclared resources (e.g., as references) or variables, i.e., all resources and variables needed in the configuration should be fully specified. Please write your complete HCL template inside <iac_template></iac_template> tags.",
+	ID:          "terraform",
+	Version:     2,
+	Target:      "terraform",
+	Description: "Single HCL program inside <iac_template> tags",
+	Text:        "You are TerraformAI, an AI agent that builds and deploys Cloud Infrastructure written in Terraform HCL. Generate a description of the Terraform program you will define, followed by a single Terraform HCL program in response to each of my Instructions. Make sure the configuration is deployable. Create IAM roles as needed. If variables are used, make sure default values are supplied. Be sure to include a valid provider configuration within a valid region. Make sure there are no undeclared resources (e.g., as references) or variables, i.e., all resources and variables needed in the configuration should be fully specified. Please write your complete HCL template inside <iac_template></iac_template> tags.",
+}
+
+var TerraformPromptV3 = Prompt{
+	ID:          "terraform",
+	Version:     3,
+	Target:      "terraform",
+	Description: "Markdown fences, secrets as sensitive variables",
+	Text:        terraformPromptV3,
 }
 
 var AnsiblePrompt = Prompt{
-	ID:   "ansible",
-	Text: "",
+	ID:     "ansible",
+	Target: "ansible",
+	Text:   "",
 }
 
 var K8sPrompt = Prompt{
-	ID:   "k8s",
-	Text: "",
+	ID:     "k8s",
+	Target: "kubernetes",
+	Text:   "",
 }
+
+// BuiltinPrompts засеваются в библиотеку промптов при старте, если их там ещё нет.
+var BuiltinPrompts = []Prompt{TerraformPrompt, TerraformPromptV2, TerraformPromptV3}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// InfraSpec — структурированные требования к инфраструктуре, из которых генерируются файлы.
+// Пустое поле означает, что требование не задано и не проверяется.
+type InfraSpec struct {
+	Cloud      string          `json:"cloud"` // aws, gcp, azure
+	Region     string          `json:"region"`
+	Components []SpecComponent `json:"components"`
+	Network    SpecNetwork     `json:"network"`
+	Security   SpecSecurity    `json:"security"`
+}
+
+type SpecComponent struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"` // vm, database, bucket, load_balancer, ...
+	Size     string `json:"size,omitempty"`
+	Count    int    `json:"count,omitempty"`
+	Subnet   string `json:"subnet,omitempty"`
+	PublicIP bool   `json:"public_ip"`
+}
+
+type SpecNetwork struct {
+	CIDR    string       `json:"cidr,omitempty"`
+	Subnets []SpecSubnet `json:"subnets,omitempty"`
+}
+
+type SpecSubnet struct {
+	Name   string `json:"name"`
+	CIDR   string `json:"cidr,omitempty"`
+	Public bool   `json:"public"`
+}
+
+type SpecSecurity struct {
+	EncryptAtRest       bool     `json:"encrypt_at_rest"`
+	AllowedIngressCIDRs []string `json:"allowed_ingress_cidrs,omitempty"`
+	Notes               []string `json:"notes,omitempty"`
+}
+
+type SpecResponse struct {
+	Spec  *InfraSpec `json:"spec"`
+	Model string     `json:"model"`
+	Usage TokenUsage `json:"usage"`
+}
+
+// PrivateOnly — в спецификации нет ни публичных подсетей, ни компонентов с публичным IP.
+func (s *InfraSpec) PrivateOnly() bool {
+	for _, sub := range s.Network.Subnets {
+		if sub.Public {
+			return false
+		}
+	}
+	for _, c := range s.Components {
+		if c.PublicIP {
+			return false
+		}
+	}
+	return len(s.Network.Subnets) > 0 || len(s.Components) > 0
+}
+
+// Component ищет компонент по имени без учёта регистра и разделителей "-"/"_".
+func (s *InfraSpec) Component(name string) *SpecComponent {
+	for i := range s.Components {
+		if specName(s.Components[i].Name) == specName(name) {
+			return &s.Components[i]
+		}
+	}
+	return nil
+}
+
+// Subnet ищет подсеть по имени так же, как Component.
+func (s *InfraSpec) Subnet(name string) *SpecSubnet {
+	for i := range s.Network.Subnets {
+		if specName(s.Network.Subnets[i].Name) == specName(name) {
+			return &s.Network.Subnets[i]
+		}
+	}
+	return nil
+}
+
+func specName(v string) string {
+	return strings.ReplaceAll(strings.ToLower(v), "-", "_")
+}
+
+func (s *InfraSpec) String() string {
+	data, _ := json.MarshalIndent(s, "", "  ")
+	return string(data)
+}
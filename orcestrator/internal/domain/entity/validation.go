@@ -0,0 +1,80 @@
+package entity
+
+import "time"
+
+// ValidationReport — итог одного прогона валидации ревизии задачи: все замечания по файлам.
+// Хранится отдельно от файлов, по одному отчёту на пару задача + ревизия.
+type ValidationReport struct {
+	JobID          string              `json:"job_id"`
+	Revision       int                 `json:"revision"`
+	Passed         bool                `json:"passed"`
+	SandboxPassed  *bool               `json:"sandbox_passed,omitempty"`
+	SecurityPassed *bool               `json:"security_passed,omitempty"`
+	Counts         SeverityCounts      `json:"counts"`
+	Files          []FileFindings      `json:"files"`
+	Suppressed     []SuppressedFinding `json:"suppressed,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+// FileFindings — замечания одного файла; File пуст у замечаний, не относящихся к файлу.
+type FileFindings struct {
+	File     string                  `json:"file"`
+	Counts   SeverityCounts          `json:"counts"`
+	Findings []ValidationConfigError `json:"findings"`
+}
+
+// SeverityCounts — число замечаний каждой серьёзности.
+type SeverityCounts struct {
+	Error   int `json:"error"`
+	Warning int `json:"warning"`
+	Info    int `json:"info"`
+}
+
+// Add учитывает замечание; без серьёзности оно считается ошибкой.
+func (c *SeverityCounts) Add(sev Severity) {
+	switch sev {
+	case SeverityWarning:
+		c.Warning++
+	case SeverityInfo:
+		c.Info++
+	default:
+		c.Error++
+	}
+}
+
+// NewValidationReport группирует замечания по файлам. Файлы из names попадают в отчёт
+// и без замечаний — так видно, что они проверялись.
+func NewValidationReport(jobID string, revision int, names []string, findings []*ValidationConfigError) *ValidationReport {
+	report := &ValidationReport{JobID: jobID, Revision: revision, CreatedAt: time.Now()}
+	index := map[string]int{}
+	file := func(name string) *FileFindings {
+		i, ok := index[name]
+		if !ok {
+			i = len(report.Files)
+			index[name] = i
+			report.Files = append(report.Files, FileFindings{File: name, Findings: []ValidationConfigError{}})
+		}
+		return &report.Files[i]
+	}
+
+	for _, name := range names {
+		file(name)
+	}
+	for _, f := range findings {
+		ff := file(f.File)
+		ff.Findings = append(ff.Findings, *f)
+		ff.Counts.Add(f.Severity)
+		report.Counts.Add(f.Severity)
+	}
+	return report
+}
+
+// FindingsFor — замечания файла из отчёта.
+func (r *ValidationReport) FindingsFor(name string) []ValidationConfigError {
+	for _, f := range r.Files {
+		if f.File == name {
+			return f.Findings
+		}
+	}
+	return nil
+}
@@ -11,4 +11,6 @@ type ConfgiFileRepository interface {
 	ListRequests(ctx context.Context) ([]string, error)
 	DeleteRequest(ctx context.Context, requestID string) error
 	GetFilesByJobID(ctx context.Context, requestID string) ([]*entity.ConfigFile, error)
+	// GetFilesByRevision возвращает файлы одной ревизии задачи.
+	GetFilesByRevision(ctx context.Context, jobID string, revision int) ([]*entity.ConfigFile, error)
 }
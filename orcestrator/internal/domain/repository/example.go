@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// ExampleRepository хранит few-shot примеры из успешных задач.
+type ExampleRepository interface {
+	// Upsert создаёт пример или заменяет существующий с тем же ID.
+	Upsert(ctx context.Context, example *entity.Example) error
+	// GetByID возвращает nil без ошибки, если примера нет.
+	GetByID(ctx context.Context, id string) (*entity.Example, error)
+	// ListByTarget возвращает примеры target; пустой target — все примеры.
+	ListByTarget(ctx context.Context, target string) ([]*entity.Example, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Embedder считает векторные представления текстов (локальный endpoint эмбеддингов).
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+	Model() string
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"orchestrator/internal/domain/entity"
+)
+
+type ExperimentRepository interface {
+	Create(ctx context.Context, exp *entity.Experiment) error
+	// GetByID возвращает nil без ошибки, если эксперимента нет.
+	GetByID(ctx context.Context, id string) (*entity.Experiment, error)
+	List(ctx context.Context) ([]*entity.Experiment, error)
+	// ActiveForTarget возвращает активный эксперимент для target или nil.
+	ActiveForTarget(ctx context.Context, target string) (*entity.Experiment, error)
+	Update(ctx context.Context, exp *entity.Experiment) error
+	Delete(ctx context.Context, id string) error
+}
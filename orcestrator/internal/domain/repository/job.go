@@ -11,6 +11,7 @@ type JobRepository interface {
 	GetByID(ctx context.Context, id string) (*entity.Job, error)
 	List(ctx context.Context) ([]*entity.Job, error)
 	ListByStatus(ctx context.Context, status entity.JobStatus) ([]*entity.Job, error)
+	ListByExperiment(ctx context.Context, experimentID string) ([]*entity.Job, error)
 	Update(ctx context.Context, job *entity.Job) error
 	UpdateStatus(ctx context.Context, id string, status entity.JobStatus) error
 	Delete(ctx context.Context, id string) error
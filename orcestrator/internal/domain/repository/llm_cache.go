@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// LLMCacheRepository хранит ответы LLM по ключу содержимого запроса.
+type LLMCacheRepository interface {
+	// Get возвращает nil без ошибки, если записи нет или срок её жизни истёк.
+	Get(ctx context.Context, key string) (*entity.GenerateResponse, error)
+	Put(ctx context.Context, key string, resp entity.GenerateResponse, ttl time.Duration) error
+}
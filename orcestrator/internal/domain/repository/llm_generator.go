@@ -11,4 +11,10 @@ type LLMGenerator interface {
 	GenerateInfrastructure(ctx context.Context, description string, prompt entity.Prompt) (entity.GenerateResponse, error)
 	// RegenerateFileWithError регенерирует файл с учетом ошибки валидации
 	RegenerateFileWithError(ctx context.Context, file entity.ConfigFile, errorMsg string, prompt entity.Prompt) (entity.ConfigFile, error)
+	// ClarifyRequirements возвращает вопросы о параметрах, которых не хватает в описании
+	ClarifyRequirements(ctx context.Context, description string, prompt entity.Prompt) (entity.ClarifyResponse, error)
+	// ExtractSpec извлекает из описания структурированную спецификацию инфраструктуры
+	ExtractSpec(ctx context.Context, description string, prompt entity.Prompt) (entity.SpecResponse, error)
+	// RefineInfrastructure дорабатывает текущие файлы по инструкции с учётом истории диалога
+	RefineInfrastructure(ctx context.Context, files []entity.ConfigFile, history []entity.ChatMessage, instruction string, prompt entity.Prompt) (entity.GenerateResponse, error)
 }
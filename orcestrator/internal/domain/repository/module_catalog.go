@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// ModuleCatalogRepository — источник модулей каталога (локальный каталог или git checkout).
+type ModuleCatalogRepository interface {
+	// Load заново читает все модули каталога.
+	Load(ctx context.Context) ([]*entity.Module, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// PromptRepository хранит версии промптов.
+type PromptRepository interface {
+	// Create сохраняет новую версию; повторная запись той же версии — ошибка.
+	Create(ctx context.Context, prompt *entity.Prompt) error
+	// Get возвращает nil без ошибки, если версии нет.
+	Get(ctx context.Context, id string, version int) (*entity.Prompt, error)
+	// Latest возвращает последнюю версию промпта или nil.
+	Latest(ctx context.Context, id string) (*entity.Prompt, error)
+	// List возвращает последние версии всех промптов.
+	List(ctx context.Context) ([]*entity.Prompt, error)
+	ListVersions(ctx context.Context, id string) ([]*entity.Prompt, error)
+	Delete(ctx context.Context, id string, version int) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// UsageRepository хранит журнал расхода токенов LLM.
+type UsageRepository interface {
+	Record(ctx context.Context, rec *entity.UsageRecord) error
+	// Sum возвращает суммарный расход владельца в интервале [from, to).
+	Sum(ctx context.Context, owner string, from, to time.Time) (entity.UsageTotals, error)
+}
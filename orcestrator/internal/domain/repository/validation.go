@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// ValidationReportRepository хранит отчёты валидации по задаче и ревизии.
+type ValidationReportRepository interface {
+	// Save создаёт отчёт или заменяет прежний отчёт той же ревизии.
+	Save(ctx context.Context, report *entity.ValidationReport) error
+	// Get возвращает nil без ошибки, если отчёта нет.
+	Get(ctx context.Context, jobID string, revision int) (*entity.ValidationReport, error)
+	DeleteByJob(ctx context.Context, jobID string) error
+}
@@ -24,45 +24,111 @@ type AmveraGenerator struct {
 	client    *http.Client
 	maxTokens int
 	verbosity string
+
+	// maxContinuations — сколько раз можно дозапросить обрезанный ответ.
+	maxContinuations int
 }
 
-func NewAmveraGenerator(apiKey, baseURL, model string) repository.LLMGenerator {
+func NewAmveraGenerator(apiKey, baseURL, model string, maxContinuations int) repository.LLMGenerator {
 	return &AmveraGenerator{
-		apiKey:    apiKey,
-		baseURL:   baseURL,
-		model:     model,
-		client:    &http.Client{Timeout: 2 * time.Minute},
-		maxTokens: 4000,
-		verbosity: "low",
+		apiKey:           apiKey,
+		baseURL:          baseURL,
+		model:            model,
+		client:           &http.Client{Timeout: 2 * time.Minute},
+		maxTokens:        4000,
+		verbosity:        "low",
+		maxContinuations: maxContinuations,
 	}
 }
 
+const continuationPrompt = "Your previous answer was cut off. Continue exactly from the point where it stopped: " +
+	"do not repeat anything already written, do not add any preamble, and close every open code block."
+
 func (g *AmveraGenerator) GenerateInfrastructure(ctx context.Context, description string, prompt entity.Prompt) (entity.GenerateResponse, error) {
-	metrics.IncLLMRequest(g.model)
 	fullPrompt := prompt.Text + " " + description
 
-	request := map[string]interface{}{
-		"model": g.model,
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": fullPrompt,
-			},
+	messages := []map[string]string{
+		{
+			"role":    "user",
+			"content": fullPrompt,
 		},
-		"temperature": 1,
-		"verbosity":   g.verbosity,
 	}
 
-	response, err := g.makeRequest(ctx, request)
-	if err != nil {
-		metrics.IncError("llm", "make_request")
-		return entity.GenerateResponse{}, fmt.Errorf("failed to make Amvera request: %w", err)
+	return g.generate(ctx, messages)
+}
+
+const refineInstructions = "Apply the change requested below to the current files. " +
+	"Return every file you modify or add in full, each in its own ```<filename> code block; " +
+	"files you do not return are kept unchanged."
+
+func (g *AmveraGenerator) RefineInfrastructure(
+	ctx context.Context,
+	files []entity.ConfigFile,
+	history []entity.ChatMessage,
+	instruction string,
+	prompt entity.Prompt,
+) (entity.GenerateResponse, error) {
+	messages := []map[string]string{
+		{"role": "system", "content": prompt.Text},
+	}
+	for _, m := range history {
+		messages = append(messages, map[string]string{"role": string(m.Role), "content": m.Content})
+	}
+
+	var b strings.Builder
+	b.WriteString("Current files:\n\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "```%s\n%s", f.Name, f.Content)
+		if !strings.HasSuffix(f.Content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n\n")
 	}
+	b.WriteString(refineInstructions)
+	b.WriteString("\n\nChange request: ")
+	b.WriteString(instruction)
+	messages = append(messages, map[string]string{"role": "user", "content": b.String()})
+
+	return g.generate(ctx, messages)
+}
 
-	files, err := g.parseResponse(response)
+// generate отправляет диалог модели, дозапрашивает обрезанный ответ и разбирает файлы.
+func (g *AmveraGenerator) generate(ctx context.Context, messages []map[string]string) (entity.GenerateResponse, error) {
+	model := modelFor(ctx, g.model)
+
+	content, finishReason, usage, err := g.chat(ctx, messages, g.verbosity)
 	if err != nil {
-		metrics.IncError("llm", "parse_response")
-		return entity.GenerateResponse{}, fmt.Errorf("failed to parse Amvera response: %w", err)
+		return entity.GenerateResponse{}, err
+	}
+
+	files, parseErrors := ExtractFiles(content)
+
+	// ответ обрезан по лимиту токенов или оборван внутри блока — дозапрашиваем продолжение
+	for i := 0; i < g.maxContinuations && (finishReason == "length" || outputTruncated(parseErrors)); i++ {
+		metrics.IncLLMContinuation(model)
+
+		continuation := append(messages[:len(messages):len(messages)],
+			map[string]string{"role": "assistant", "content": content},
+			map[string]string{"role": "user", "content": continuationPrompt},
+		)
+
+		var more string
+		var moreUsage entity.TokenUsage
+		more, finishReason, moreUsage, err = g.chat(ctx, continuation, g.verbosity)
+		if err != nil {
+			return entity.GenerateResponse{}, fmt.Errorf("continuation %d: %w", i+1, err)
+		}
+		usage.Add(moreUsage)
+
+		content = stitchContinuation(content, more)
+		files, parseErrors = ExtractFiles(content)
+	}
+
+	if finishReason == "length" && !outputTruncated(parseErrors) {
+		parseErrors = append(parseErrors, &entity.ValidationConfigError{
+			File:    llmOutputFile,
+			Message: fmt.Sprintf("model stopped at the token limit after %d continuations: %s", g.maxContinuations, truncatedMarker),
+		})
 	}
 
 	return entity.GenerateResponse{
@@ -70,16 +136,51 @@ func (g *AmveraGenerator) GenerateInfrastructure(ctx context.Context, descriptio
 		RequestID: uuid.NewString(),
 		CreatedAt: time.Now().UTC(),
 		Status:    "success",
+		Model:     model,
+		Usage:     usage,
+
+		ParseErrors: parseErrors,
 	}, nil
 }
 
+// chat выполняет один запрос к модели и возвращает текст ответа, finish_reason и расход токенов.
+func (g *AmveraGenerator) chat(ctx context.Context, messages []map[string]string, verbosity string) (string, string, entity.TokenUsage, error) {
+	model := modelFor(ctx, g.model)
+	metrics.IncLLMRequest(model)
+
+	request := map[string]interface{}{
+		"model":       model,
+		"messages":    messages,
+		"temperature": 1,
+		"verbosity":   verbosity,
+	}
+
+	response, err := g.makeRequest(ctx, request)
+	if err != nil {
+		metrics.IncError("llm", "make_request")
+		return "", "", entity.TokenUsage{}, fmt.Errorf("failed to make Amvera request: %w", err)
+	}
+
+	content, err := responseContent(response)
+	if err != nil {
+		metrics.IncError("llm", "parse_response")
+		return "", "", entity.TokenUsage{}, fmt.Errorf("failed to parse Amvera response: %w", err)
+	}
+
+	usage := parseUsage(response)
+	metrics.AddLLMTokens(model, usage.PromptTokens, usage.CompletionTokens)
+
+	return content, finishReason(response), usage, nil
+}
+
 func (g *AmveraGenerator) RegenerateFileWithError(ctx context.Context, file entity.ConfigFile, errorMsg string, prompt entity.Prompt) (entity.ConfigFile, error) {
-	metrics.IncLLMRequest(g.model)
+	model := modelFor(ctx, g.model)
+	metrics.IncLLMRequest(model)
 
 	regeneratePrompt := fmt.Sprintf("Please fix the following %s file based on the validation errors:\n\nOriginal file content:\n```\n%s\n```\n\nValidation errors:\n%s\n\nPlease provide the corrected file content only, without any explanations or markdown formatting.", file.Type, file.Content, errorMsg)
 
 	request := map[string]interface{}{
-		"model": g.model,
+		"model": model,
 		"messages": []map[string]string{
 			{
 				"role":    "user",
@@ -104,7 +205,7 @@ func (g *AmveraGenerator) RegenerateFileWithError(ctx context.Context, file enti
 
 	file.Content = correctedContent
 	file.HasError = false
-	file.ErrorMsg = nil
+	file.Findings = nil
 
 	return file, nil
 }
@@ -152,129 +253,76 @@ func (g *AmveraGenerator) makeRequest(ctx context.Context, request map[string]in
 	return response, nil
 }
 
-func (g *AmveraGenerator) parseResponse(response map[string]interface{}) ([]*entity.ConfigFile, error) {
+func responseContent(response map[string]interface{}) (string, error) {
 	choices, ok := response["choices"].([]interface{})
 	if !ok || len(choices) == 0 {
-		return nil, fmt.Errorf("invalid response format: no choices")
+		return "", fmt.Errorf("invalid response format: no choices")
 	}
 
 	choice, ok := choices[0].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid response format: invalid choice")
+		return "", fmt.Errorf("invalid response format: invalid choice")
 	}
 
 	message, ok := choice["message"].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid response format: no message")
+		return "", fmt.Errorf("invalid response format: no message")
 	}
 
 	content, ok := message["content"].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid response format: no content")
-	}
-
-	files := g.extractFilesFromContent(content)
-
-	if len(files) == 0 {
-		files = []*entity.ConfigFile{
-			{
-				JobID:    "",
-				Name:     "main.tf",
-				Content:  content,
-				Type:     "terraform",
-				HasError: false,
-				ErrorMsg: nil,
-			},
-		}
+		return "", fmt.Errorf("invalid response format: no content")
 	}
 
-	return files, nil
+	return content, nil
 }
 
-func (g *AmveraGenerator) parseSingleFileResponse(response map[string]interface{}) (string, error) {
+func finishReason(response map[string]interface{}) string {
 	choices, ok := response["choices"].([]interface{})
 	if !ok || len(choices) == 0 {
-		return "", fmt.Errorf("invalid response format: no choices")
+		return ""
 	}
-
 	choice, ok := choices[0].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("invalid response format: invalid choice")
+		return ""
 	}
+	reason, _ := choice["finish_reason"].(string)
+	return reason
+}
 
-	message, ok := choice["message"].(map[string]interface{})
+// parseUsage достаёт расход токенов из блока usage ответа (OpenAI-совместимый формат).
+func parseUsage(response map[string]interface{}) entity.TokenUsage {
+	usage, ok := response["usage"].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("invalid response format: no message")
+		return entity.TokenUsage{}
 	}
 
-	content, ok := message["content"].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid response format: no content")
+	toInt := func(key string) int64 {
+		v, _ := usage[key].(float64)
+		return int64(v)
 	}
 
-	return strings.TrimSpace(content), nil
-}
-
-func (g *AmveraGenerator) extractFilesFromContent(content string) []*entity.ConfigFile {
-	var files []*entity.ConfigFile
-
-	lines := strings.Split(content, "\n")
-	var currentFile *entity.ConfigFile
-	var inCodeBlock bool
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.HasPrefix(line, "```") {
-			if inCodeBlock && currentFile != nil {
-
-				files = append(files, currentFile)
-				currentFile = nil
-			} else {
-				fileName := strings.TrimPrefix(line, "```")
-				if fileName == "" {
-					fileName = "main.tf"
-				}
-
-				currentFile = &entity.ConfigFile{
-					Name:     fileName,
-					Content:  "",
-					Type:     g.detectFileType(fileName),
-					HasError: false,
-					ErrorMsg: nil,
-				}
-			}
-			inCodeBlock = !inCodeBlock
-			continue
-		}
-
-		if inCodeBlock && currentFile != nil {
-			if currentFile.Content != "" {
-				currentFile.Content += "\n"
-			}
-			currentFile.Content += line
-		}
+	u := entity.TokenUsage{
+		PromptTokens:     toInt("prompt_tokens"),
+		CompletionTokens: toInt("completion_tokens"),
+		TotalTokens:      toInt("total_tokens"),
 	}
-
-	if currentFile != nil {
-		files = append(files, currentFile)
+	if u.TotalTokens == 0 {
+		u.TotalTokens = u.PromptTokens + u.CompletionTokens
 	}
-
-	return files
+	return u
 }
 
-func (g *AmveraGenerator) detectFileType(fileName string) string {
-	fileName = strings.ToLower(fileName)
-
-	if strings.HasSuffix(fileName, ".tf") {
-		return "terraform"
-	}
-	if strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, ".yml") {
-		return "kubernetes"
+func (g *AmveraGenerator) parseSingleFileResponse(response map[string]interface{}) (string, error) {
+	content, err := responseContent(response)
+	if err != nil {
+		return "", err
 	}
-	if strings.HasSuffix(fileName, ".yml") || strings.HasSuffix(fileName, ".yaml") {
-		return "ansible"
+
+	// модель нередко всё равно оборачивает файл в блок кода
+	if files, problems := extractFencedFiles(content, 0, "file"); len(files) == 1 && len(problems) == 0 {
+		return files[0].Content, nil
 	}
 
-	return "unknown"
+	return strings.TrimSpace(content) + "\n", nil
 }
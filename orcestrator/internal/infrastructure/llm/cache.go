@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+)
+
+// CachingGenerator — декоратор LLMGenerator, который отдаёт сохранённый ответ
+// для одинаковых (модель, промпт, описание) вместо повторного запроса к LLM.
+type CachingGenerator struct {
+	next   repository.LLMGenerator
+	store  repository.LLMCacheRepository
+	model  string
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+func NewCachingGenerator(
+	next repository.LLMGenerator,
+	store repository.LLMCacheRepository,
+	model string,
+	ttl time.Duration,
+	logger *slog.Logger,
+) repository.LLMGenerator {
+	return &CachingGenerator{
+		next:   next,
+		store:  store,
+		model:  model,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+func (g *CachingGenerator) GenerateInfrastructure(ctx context.Context, description string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	if cacheBypassed(ctx) {
+		return g.next.GenerateInfrastructure(ctx, description, prompt)
+	}
+
+	key := CacheKey(modelFor(ctx, g.model), prompt, description)
+
+	cached, err := g.store.Get(ctx, key)
+	if err != nil {
+		// кэш не должен ломать генерацию
+		g.logger.Warn("llm cache get failed", "key", key, "err", err)
+		metrics.IncError("llm_cache", "get")
+	}
+	if cached != nil {
+		metrics.IncLLMCache("hit")
+		resp := *cached
+		resp.CacheHit = true
+		resp.Usage = entity.TokenUsage{}
+		return resp, nil
+	}
+	metrics.IncLLMCache("miss")
+
+	resp, err := g.next.GenerateInfrastructure(ctx, description, prompt)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := g.store.Put(ctx, key, resp, g.ttl); err != nil {
+		g.logger.Warn("llm cache put failed", "key", key, "err", err)
+		metrics.IncError("llm_cache", "put")
+	}
+	return resp, nil
+}
+
+func (g *CachingGenerator) ClarifyRequirements(ctx context.Context, description string, prompt entity.Prompt) (entity.ClarifyResponse, error) {
+	return g.next.ClarifyRequirements(ctx, description, prompt)
+}
+
+func (g *CachingGenerator) ExtractSpec(ctx context.Context, description string, prompt entity.Prompt) (entity.SpecResponse, error) {
+	return g.next.ExtractSpec(ctx, description, prompt)
+}
+
+// RefineInfrastructure не кэшируется: доработка зависит от текущих файлов и всей истории диалога.
+func (g *CachingGenerator) RefineInfrastructure(ctx context.Context, files []entity.ConfigFile, history []entity.ChatMessage, instruction string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	return g.next.RefineInfrastructure(ctx, files, history, instruction, prompt)
+}
+
+func (g *CachingGenerator) RegenerateFileWithError(ctx context.Context, file entity.ConfigFile, errorMsg string, prompt entity.Prompt) (entity.ConfigFile, error) {
+	return g.next.RegenerateFileWithError(ctx, file, errorMsg, prompt)
+}
+
+// CacheKey — адрес ответа по содержимому запроса: модель, ID и версия промпта и
+// нормализованное описание. Хэш текста промпта защищает от правок без смены версии.
+func CacheKey(model string, prompt entity.Prompt, description string) string {
+	promptHash := sha256.Sum256([]byte(prompt.Text))
+
+	h := sha256.New()
+	for _, part := range []string{
+		model,
+		fmt.Sprintf("%s@%d", prompt.ID, prompt.Version),
+		hex.EncodeToString(promptHash[:]),
+		normalizeDescription(description),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeDescription схлопывает только пробелы: регистр значим (имена бакетов, теги, пароли).
+func normalizeDescription(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
@@ -0,0 +1,296 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+// ErrCassetteNotFound — в режиме replay для запроса нет записанного ответа.
+var ErrCassetteNotFound = errors.New("cassette not found")
+
+const (
+	methodGenerate   = "generate"
+	methodRegenerate = "regenerate"
+	methodRefine     = "refine"
+	methodClarify    = "clarify"
+	methodSpec       = "spec"
+)
+
+// cassette — записанный ответ LLM на один запрос. Входные данные сохраняются
+// рядом с ответом, чтобы кассету можно было прочитать и поправить руками.
+type cassette struct {
+	Key        string    `json:"key"`
+	Method     string    `json:"method"`
+	Model      string    `json:"model"`
+	PromptID   string    `json:"prompt_id"`
+	Input      string    `json:"input"`
+	RecordedAt time.Time `json:"recorded_at"`
+
+	Response      *entity.GenerateResponse `json:"response,omitempty"`
+	File          *entity.ConfigFile       `json:"file,omitempty"`
+	Clarification *entity.ClarifyResponse  `json:"clarification,omitempty"`
+	Spec          *entity.SpecResponse     `json:"spec,omitempty"`
+}
+
+type cassetteDir string
+
+func (d cassetteDir) path(method, key string) string {
+	return filepath.Join(string(d), fmt.Sprintf("%s-%s.json", method, key))
+}
+
+func (d cassetteDir) load(method, key string) (*cassette, error) {
+	data, err := os.ReadFile(d.path(method, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s %s", ErrCassetteNotFound, method, key)
+		}
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unmarshal cassette %s: %w", key, err)
+	}
+	return &c, nil
+}
+
+func (d cassetteDir) save(c *cassette) error {
+	if err := os.MkdirAll(string(d), 0755); err != nil {
+		return fmt.Errorf("create cassette dir: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal cassette: %w", err)
+	}
+	if err := os.WriteFile(d.path(c.Method, c.Key), data, 0644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+// ReplayGenerator отдаёт ответы из кассет на диске и никогда не ходит в сеть.
+type ReplayGenerator struct {
+	dir   cassetteDir
+	model string
+}
+
+func NewReplayGenerator(dir, model string) repository.LLMGenerator {
+	return &ReplayGenerator{dir: cassetteDir(dir), model: model}
+}
+
+func (g *ReplayGenerator) GenerateInfrastructure(ctx context.Context, description string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	c, err := g.dir.load(methodGenerate, CacheKey(modelFor(ctx, g.model), prompt, description))
+	if err != nil {
+		return entity.GenerateResponse{}, err
+	}
+	if c.Response == nil {
+		return entity.GenerateResponse{}, fmt.Errorf("cassette %s has no response", c.Key)
+	}
+	resp := *c.Response
+	// в режиме replay токены не расходуются
+	resp.Usage = entity.TokenUsage{}
+	return resp, nil
+}
+
+func (g *ReplayGenerator) RegenerateFileWithError(ctx context.Context, file entity.ConfigFile, errorMsg string, prompt entity.Prompt) (entity.ConfigFile, error) {
+	c, err := g.dir.load(methodRegenerate, regenerateKey(modelFor(ctx, g.model), prompt, file, errorMsg))
+	if err != nil {
+		return file, err
+	}
+	if c.File == nil {
+		return file, fmt.Errorf("cassette %s has no file", c.Key)
+	}
+	fixed := *c.File
+	fixed.JobID = file.JobID
+	return fixed, nil
+}
+
+func (g *ReplayGenerator) ClarifyRequirements(ctx context.Context, description string, prompt entity.Prompt) (entity.ClarifyResponse, error) {
+	c, err := g.dir.load(methodClarify, CacheKey(modelFor(ctx, g.model), prompt, description))
+	if err != nil {
+		return entity.ClarifyResponse{}, err
+	}
+	if c.Clarification == nil {
+		return entity.ClarifyResponse{}, fmt.Errorf("cassette %s has no clarification", c.Key)
+	}
+	resp := *c.Clarification
+	resp.Usage = entity.TokenUsage{}
+	return resp, nil
+}
+
+func (g *ReplayGenerator) ExtractSpec(ctx context.Context, description string, prompt entity.Prompt) (entity.SpecResponse, error) {
+	c, err := g.dir.load(methodSpec, CacheKey(modelFor(ctx, g.model), prompt, description))
+	if err != nil {
+		return entity.SpecResponse{}, err
+	}
+	if c.Spec == nil {
+		return entity.SpecResponse{}, fmt.Errorf("cassette %s has no spec", c.Key)
+	}
+	resp := *c.Spec
+	resp.Usage = entity.TokenUsage{}
+	return resp, nil
+}
+
+func (g *ReplayGenerator) RefineInfrastructure(ctx context.Context, files []entity.ConfigFile, history []entity.ChatMessage, instruction string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	c, err := g.dir.load(methodRefine, refineKey(modelFor(ctx, g.model), prompt, files, history, instruction))
+	if err != nil {
+		return entity.GenerateResponse{}, err
+	}
+	if c.Response == nil {
+		return entity.GenerateResponse{}, fmt.Errorf("cassette %s has no response", c.Key)
+	}
+	resp := *c.Response
+	resp.Usage = entity.TokenUsage{}
+	return resp, nil
+}
+
+// RecordingGenerator проксирует запросы в реальный генератор и записывает ответы в кассеты.
+type RecordingGenerator struct {
+	next  repository.LLMGenerator
+	dir   cassetteDir
+	model string
+}
+
+func NewRecordingGenerator(next repository.LLMGenerator, dir, model string) repository.LLMGenerator {
+	return &RecordingGenerator{next: next, dir: cassetteDir(dir), model: model}
+}
+
+func (g *RecordingGenerator) GenerateInfrastructure(ctx context.Context, description string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	resp, err := g.next.GenerateInfrastructure(ctx, description, prompt)
+	if err != nil {
+		return resp, err
+	}
+
+	c := &cassette{
+		Key:        CacheKey(modelFor(ctx, g.model), prompt, description),
+		Method:     methodGenerate,
+		Model:      modelFor(ctx, g.model),
+		PromptID:   prompt.ID,
+		Input:      description,
+		RecordedAt: time.Now().UTC(),
+		Response:   &resp,
+	}
+	if err := g.dir.save(c); err != nil {
+		return resp, fmt.Errorf("record cassette: %w", err)
+	}
+	return resp, nil
+}
+
+func (g *RecordingGenerator) RegenerateFileWithError(ctx context.Context, file entity.ConfigFile, errorMsg string, prompt entity.Prompt) (entity.ConfigFile, error) {
+	fixed, err := g.next.RegenerateFileWithError(ctx, file, errorMsg, prompt)
+	if err != nil {
+		return fixed, err
+	}
+
+	c := &cassette{
+		Key:        regenerateKey(modelFor(ctx, g.model), prompt, file, errorMsg),
+		Method:     methodRegenerate,
+		Model:      modelFor(ctx, g.model),
+		PromptID:   prompt.ID,
+		Input:      errorMsg,
+		RecordedAt: time.Now().UTC(),
+		File:       &fixed,
+	}
+	if err := g.dir.save(c); err != nil {
+		return fixed, fmt.Errorf("record cassette: %w", err)
+	}
+	return fixed, nil
+}
+
+func (g *RecordingGenerator) ClarifyRequirements(ctx context.Context, description string, prompt entity.Prompt) (entity.ClarifyResponse, error) {
+	resp, err := g.next.ClarifyRequirements(ctx, description, prompt)
+	if err != nil {
+		return resp, err
+	}
+
+	c := &cassette{
+		Key:           CacheKey(modelFor(ctx, g.model), prompt, description),
+		Method:        methodClarify,
+		Model:         modelFor(ctx, g.model),
+		PromptID:      prompt.ID,
+		Input:         description,
+		RecordedAt:    time.Now().UTC(),
+		Clarification: &resp,
+	}
+	if err := g.dir.save(c); err != nil {
+		return resp, fmt.Errorf("record cassette: %w", err)
+	}
+	return resp, nil
+}
+
+func (g *RecordingGenerator) ExtractSpec(ctx context.Context, description string, prompt entity.Prompt) (entity.SpecResponse, error) {
+	resp, err := g.next.ExtractSpec(ctx, description, prompt)
+	if err != nil {
+		return resp, err
+	}
+
+	c := &cassette{
+		Key:        CacheKey(modelFor(ctx, g.model), prompt, description),
+		Method:     methodSpec,
+		Model:      modelFor(ctx, g.model),
+		PromptID:   prompt.ID,
+		Input:      description,
+		RecordedAt: time.Now().UTC(),
+		Spec:       &resp,
+	}
+	if err := g.dir.save(c); err != nil {
+		return resp, fmt.Errorf("record cassette: %w", err)
+	}
+	return resp, nil
+}
+
+func (g *RecordingGenerator) RefineInfrastructure(ctx context.Context, files []entity.ConfigFile, history []entity.ChatMessage, instruction string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	resp, err := g.next.RefineInfrastructure(ctx, files, history, instruction, prompt)
+	if err != nil {
+		return resp, err
+	}
+
+	c := &cassette{
+		Key:        refineKey(modelFor(ctx, g.model), prompt, files, history, instruction),
+		Method:     methodRefine,
+		Model:      modelFor(ctx, g.model),
+		PromptID:   prompt.ID,
+		Input:      instruction,
+		RecordedAt: time.Now().UTC(),
+		Response:   &resp,
+	}
+	if err := g.dir.save(c); err != nil {
+		return resp, fmt.Errorf("record cassette: %w", err)
+	}
+	return resp, nil
+}
+
+func refineKey(model string, prompt entity.Prompt, files []entity.ConfigFile, history []entity.ChatMessage, instruction string) string {
+	h := sha256.New()
+	parts := []string{model, prompt.ID, prompt.Text}
+	for _, f := range files {
+		parts = append(parts, f.Name, f.Content)
+	}
+	for _, m := range history {
+		parts = append(parts, string(m.Role), m.Content)
+	}
+	parts = append(parts, instruction)
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func regenerateKey(model string, prompt entity.Prompt, file entity.ConfigFile, errorMsg string) string {
+	h := sha256.New()
+	for _, part := range []string{model, prompt.ID, prompt.Text, file.Name, file.Content, errorMsg} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
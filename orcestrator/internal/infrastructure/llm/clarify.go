@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+)
+
+const clarifyPrompt = "Before writing any code, review the infrastructure request below and list the parameters " +
+	"that are missing or ambiguous and would otherwise have to be guessed: regions, instance sizes, CIDR ranges, " +
+	"counts, versions, naming, access rules. Do not generate any files. " +
+	"Reply with JSON only: {\"questions\": [{\"id\": \"region\", \"question\": \"Which region?\", \"default\": \"eu-central-1\"}]}. " +
+	"Use short snake_case ids and put your best guess into \"default\". " +
+	"If nothing important is missing, reply with {\"questions\": []}.\n\nRequest: "
+
+var questionIDRe = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func (g *AmveraGenerator) ClarifyRequirements(ctx context.Context, description string, prompt entity.Prompt) (entity.ClarifyResponse, error) {
+	messages := []map[string]string{
+		{"role": "system", "content": prompt.Text},
+		{"role": "user", "content": clarifyPrompt + description},
+	}
+
+	content, _, usage, err := g.chat(ctx, messages, g.verbosity)
+	if err != nil {
+		return entity.ClarifyResponse{}, err
+	}
+
+	questions, err := parseQuestions(content)
+	if err != nil {
+		return entity.ClarifyResponse{Model: modelFor(ctx, g.model), Usage: usage}, err
+	}
+	return entity.ClarifyResponse{
+		Questions: questions,
+		Model:     modelFor(ctx, g.model),
+		Usage:     usage,
+	}, nil
+}
+
+// parseQuestions разбирает JSON со списком вопросов; допускается обёртка в блок кода и голый массив.
+func parseQuestions(content string) ([]entity.ClarifyingQuestion, error) {
+	trimmed := unfenceJSON(content)
+
+	var questions []entity.ClarifyingQuestion
+	var wrapped struct {
+		Questions []entity.ClarifyingQuestion `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(trimmed), &wrapped); err == nil {
+		questions = wrapped.Questions
+	} else if err := json.Unmarshal([]byte(trimmed), &questions); err != nil {
+		return nil, fmt.Errorf("clarifying questions are not valid JSON: %w", err)
+	}
+
+	seen := make(map[string]bool, len(questions))
+	result := questions[:0]
+	for i, q := range questions {
+		q.Question = strings.TrimSpace(q.Question)
+		if q.Question == "" {
+			continue
+		}
+		q.ID = strings.Trim(questionIDRe.ReplaceAllString(strings.ToLower(q.ID), "_"), "_")
+		if q.ID == "" || seen[q.ID] {
+			q.ID = fmt.Sprintf("q%d", i+1)
+		}
+		seen[q.ID] = true
+		q.Answer = ""
+		result = append(result, q)
+	}
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package llm
+
+import "context"
+
+type ctxKey int
+
+const (
+	ctxKeyNoCache ctxKey = iota
+	ctxKeyModel
+)
+
+// WithoutCache помечает запрос как не использующий кэш ответов (per-job bypass).
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyNoCache, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	v, _ := ctx.Value(ctxKeyNoCache).(bool)
+	return v
+}
+
+// WithModel переопределяет модель для запросов в этом контексте (например, вариант эксперимента).
+func WithModel(ctx context.Context, model string) context.Context {
+	if model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKeyModel, model)
+}
+
+func modelFor(ctx context.Context, fallback string) string {
+	if model, ok := ctx.Value(ctxKeyModel).(string); ok && model != "" {
+		return model
+	}
+	return fallback
+}
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// maxContinuationOverlap ограничивает поиск повторённого хвоста при склейке.
+const maxContinuationOverlap = 2000
+
+// minContinuationOverlap — более короткие совпадения считаем случайными.
+const minContinuationOverlap = 16
+
+// outputTruncated сообщает, что разбор обнаружил незакрытый блок — признак обрезанного вывода.
+func outputTruncated(problems []*entity.ValidationConfigError) bool {
+	for _, p := range problems {
+		if strings.Contains(p.Message, truncatedMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// stitchContinuation приклеивает продолжение к оборванному ответу. Модель
+// иногда заново открывает текущий блок кода или повторяет последние строки —
+// такие повторы отбрасываются, чтобы файлы склеились без дублей.
+func stitchContinuation(prev, next string) string {
+	if open := lastOpenFence(prev); open != "" {
+		firstLine, rest, found := strings.Cut(next, "\n")
+		if found && strings.TrimSpace(firstLine) == open {
+			next = rest
+		}
+	}
+
+	limit := min(len(prev), len(next), maxContinuationOverlap)
+	for k := limit; k >= minContinuationOverlap; k-- {
+		if strings.HasSuffix(prev, next[:k]) {
+			next = next[k:]
+			break
+		}
+	}
+
+	return prev + next
+}
+
+// lastOpenFence возвращает строку открытия блока, внутри которого оборван текст, или "".
+func lastOpenFence(content string) string {
+	var open, fence string
+	for _, line := range strings.Split(content, "\n") {
+		if open == "" {
+			if marker, _, ok := openingFence(line); ok {
+				open, fence = strings.TrimSpace(line), marker
+			}
+			continue
+		}
+		if isClosingFence(line, fence) {
+			open = ""
+		}
+	}
+	return open
+}
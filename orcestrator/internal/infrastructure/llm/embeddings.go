@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+)
+
+// HTTPEmbedder — клиент OpenAI-совместимого endpoint эмбеддингов (POST {model, input}),
+// например локального Ollama или text-embeddings-inference.
+type HTTPEmbedder struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+func NewHTTPEmbedder(url, model, apiKey string) repository.Embedder {
+	return &HTTPEmbedder{
+		url:    url,
+		model:  model,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (e *HTTPEmbedder) Model() string {
+	return e.model
+}
+
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		metrics.IncError("embeddings", "http_do")
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Printf("close body err: %s", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		metrics.IncError("embeddings", fmt.Sprintf("api_error_%d", resp.StatusCode))
+		return nil, fmt.Errorf("embeddings api error: %d - %s", resp.StatusCode, string(msg))
+	}
+
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		metrics.IncError("embeddings", "decode_response")
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings api returned %d vectors for %d inputs", len(out.Data), len(texts))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings api returned invalid index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
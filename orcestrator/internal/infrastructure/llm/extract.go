@@ -0,0 +1,352 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// llmOutputFile — имя, под которым в ошибках разбора фигурирует сам ответ модели.
+const llmOutputFile = "llm_output"
+
+var (
+	iacTemplateOpenRe = regexp.MustCompile(`<iac_template(\s+name="([^"]*)")?\s*>`)
+	iacTemplateClose  = "</iac_template>"
+
+	// "# main.tf", "# File: main.tf", "// filename: main.tf" в первой строке блока
+	fileNameCommentRe = regexp.MustCompile(`^\s*(?:#|//)\s*(?:file(?:name)?\s*:\s*)?([\w.-]+\.\w+)\s*$`)
+	// title="main.tf" / file=main.tf в info-строке блока
+	infoAttrRe = regexp.MustCompile(`(?:title|file|filename|name)=["']?([^"'\s]+)["']?`)
+)
+
+// ExtractFiles разбирает ответ модели в набор файлов, сохраняя их содержимое байт в байт.
+// Поддерживаются три формата вывода:
+//   - JSON: {"files": [{"name": "main.tf", "content": "..."}]};
+//   - теги <iac_template> (TerraformPromptV2), внутри — HCL или Markdown-блоки;
+//   - Markdown-блоки ```<filename> (TerraformPrompt, TerraformPromptV3).
+//
+// Проблемы разбора (обрезанный вывод, блок без имени файла, дубликаты) не
+// угадываются, а возвращаются как ошибки валидации.
+func ExtractFiles(content string) ([]*entity.ConfigFile, []*entity.ValidationConfigError) {
+	if files, ok := extractJSONFiles(content); ok {
+		return validateExtracted(files, nil)
+	}
+
+	if strings.Contains(content, "<iac_template") {
+		files, problems := extractIaCTemplates(content)
+		return validateExtracted(files, problems)
+	}
+
+	files, problems := extractFencedFiles(content, 0, "")
+	return validateExtracted(files, problems)
+}
+
+// truncatedMarker входит в текст ошибки незакрытого блока.
+const truncatedMarker = "output is truncated"
+
+type jsonFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	Type    string `json:"type"`
+}
+
+func extractJSONFiles(content string) ([]*entity.ConfigFile, bool) {
+	trimmed := unfenceJSON(content)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	var raw []jsonFile
+	var wrapped struct {
+		Files []jsonFile `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(trimmed), &wrapped); err == nil && len(wrapped.Files) > 0 {
+		raw = wrapped.Files
+	} else if err := json.Unmarshal([]byte(trimmed), &raw); err != nil || len(raw) == 0 {
+		return nil, false
+	}
+
+	files := make([]*entity.ConfigFile, 0, len(raw))
+	for _, f := range raw {
+		fileType := f.Type
+		if fileType == "" {
+			fileType = detectFileType(f.Name)
+		}
+		files = append(files, &entity.ConfigFile{
+			Name:    f.Name,
+			Content: f.Content,
+			Type:    fileType,
+		})
+	}
+	return files, true
+}
+
+// unfenceJSON снимает обёртку ```json ... ```, в которую модель любит заворачивать JSON.
+func unfenceJSON(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "```") {
+		if nl := strings.IndexByte(trimmed, '\n'); nl >= 0 && strings.HasSuffix(trimmed, "```") {
+			trimmed = strings.TrimSpace(trimmed[nl+1 : len(trimmed)-3])
+		}
+	}
+	return trimmed
+}
+
+func extractIaCTemplates(content string) ([]*entity.ConfigFile, []*entity.ValidationConfigError) {
+	var files []*entity.ConfigFile
+	var problems []*entity.ValidationConfigError
+
+	rest := content
+	offset := 0
+	for {
+		loc := iacTemplateOpenRe.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		name := ""
+		if loc[4] >= 0 {
+			name = rest[loc[4]:loc[5]]
+		}
+		bodyStart := loc[1]
+		startLine := lineAt(content, offset+loc[0])
+
+		end := strings.Index(rest[bodyStart:], iacTemplateClose)
+		if end < 0 {
+			problems = append(problems, &entity.ValidationConfigError{
+				File:    llmOutputFile,
+				Message: fmt.Sprintf("<iac_template> opened at line %d is never closed: %s", startLine, truncatedMarker),
+				Line:    startLine,
+			})
+			body := rest[bodyStart:]
+			files = append(files, templateFiles(body, name, offset+bodyStart, content, &problems)...)
+			break
+		}
+
+		body := rest[bodyStart : bodyStart+end]
+		files = append(files, templateFiles(body, name, offset+bodyStart, content, &problems)...)
+
+		consumed := bodyStart + end + len(iacTemplateClose)
+		rest = rest[consumed:]
+		offset += consumed
+	}
+
+	return files, problems
+}
+
+// templateFiles превращает тело <iac_template> в файлы: если внутри есть
+// Markdown-блоки, разбираем их, иначе тело целиком — один HCL файл.
+func templateFiles(body, name string, bodyOffset int, content string, problems *[]*entity.ValidationConfigError) []*entity.ConfigFile {
+	if strings.Contains(body, "```") {
+		files, p := extractFencedFiles(body, lineAt(content, bodyOffset)-1, name)
+		*problems = append(*problems, p...)
+		return files
+	}
+
+	if name == "" {
+		// TerraformPromptV2 просит ровно одну HCL программу — это main.tf по определению
+		name = "main.tf"
+	}
+	return []*entity.ConfigFile{{
+		Name:    name,
+		Content: strings.TrimLeft(strings.TrimRight(body, " \t\n\r")+"\n", "\r\n"),
+		Type:    detectFileType(name),
+	}}
+}
+
+// extractFencedFiles разбирает Markdown-блоки по правилам CommonMark: закрывающая
+// строка — только символы ограждения не короче открывающих, без info-строки.
+// lineOffset сдвигает номера строк в ошибках, defaultName используется для
+// единственного безымянного блока внутри именованного <iac_template>.
+func extractFencedFiles(content string, lineOffset int, defaultName string) ([]*entity.ConfigFile, []*entity.ValidationConfigError) {
+	var files []*entity.ConfigFile
+	var problems []*entity.ValidationConfigError
+
+	lines := strings.SplitAfter(content, "\n")
+
+	var (
+		current   *entity.ConfigFile
+		body      strings.Builder
+		fence     string
+		openLine  int
+		unnamed   bool
+		firstLine bool
+	)
+
+	for i, rawLine := range lines {
+		lineNo := lineOffset + i + 1
+		line := strings.TrimRight(rawLine, "\r\n")
+
+		if current == nil {
+			marker, info, ok := openingFence(line)
+			if !ok {
+				continue
+			}
+			fence, openLine = marker, lineNo
+			name := fileNameFromInfo(info)
+			unnamed = name == ""
+			firstLine = true
+			body.Reset()
+			current = &entity.ConfigFile{Name: name}
+			continue
+		}
+
+		if isClosingFence(line, fence) {
+			files, problems = finishFencedFile(files, problems, current, body.String(), openLine, unnamed, defaultName)
+			current = nil
+			continue
+		}
+
+		if firstLine && unnamed {
+			// имя файла может прийти комментарием в первой строке блока
+			if m := fileNameCommentRe.FindStringSubmatch(line); m != nil {
+				current.Name = m[1]
+				unnamed = false
+			}
+		}
+		firstLine = false
+		body.WriteString(rawLine)
+	}
+
+	if current != nil {
+		problems = append(problems, &entity.ValidationConfigError{
+			File:    nameOr(current.Name, llmOutputFile),
+			Message: fmt.Sprintf("code block opened at line %d is never closed: %s", openLine, truncatedMarker),
+			Line:    openLine,
+		})
+		files, problems = finishFencedFile(files, problems, current, body.String(), openLine, unnamed, defaultName)
+	}
+
+	return files, problems
+}
+
+func finishFencedFile(
+	files []*entity.ConfigFile,
+	problems []*entity.ValidationConfigError,
+	file *entity.ConfigFile,
+	content string,
+	openLine int,
+	unnamed bool,
+	defaultName string,
+) ([]*entity.ConfigFile, []*entity.ValidationConfigError) {
+	if unnamed {
+		if defaultName == "" {
+			problems = append(problems, &entity.ValidationConfigError{
+				File:    llmOutputFile,
+				Message: fmt.Sprintf("code block at line %d has no file name; expected ```<filename>", openLine),
+				Line:    openLine,
+			})
+			return files, problems
+		}
+		file.Name = defaultName
+	}
+	file.Content = content
+	file.Type = detectFileType(file.Name)
+	return append(files, file), problems
+}
+
+// openingFence распознаёт строку вида ```info или ~~~info (до трёх пробелов отступа).
+func openingFence(line string) (marker, info string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return "", "", false
+	}
+	for _, ch := range []byte{'`', '~'} {
+		n := 0
+		for n < len(trimmed) && trimmed[n] == ch {
+			n++
+		}
+		if n >= 3 {
+			info = strings.TrimSpace(trimmed[n:])
+			if ch == '`' && strings.Contains(info, "`") {
+				return "", "", false
+			}
+			return trimmed[:n], info, true
+		}
+	}
+	return "", "", false
+}
+
+func isClosingFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) < len(fence) {
+		return false
+	}
+	return strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// fileNameFromInfo достаёт имя файла из info-строки: "main.tf", "hcl main.tf",
+// "terraform title=\"main.tf\"". Одно название языка ("hcl") именем файла не является.
+func fileNameFromInfo(info string) string {
+	if info == "" {
+		return ""
+	}
+	if m := infoAttrRe.FindStringSubmatch(info); m != nil {
+		return m[1]
+	}
+	for _, field := range strings.Fields(info) {
+		field = strings.Trim(field, "{}:")
+		if strings.Contains(field, ".") {
+			return field
+		}
+	}
+	return ""
+}
+
+func validateExtracted(files []*entity.ConfigFile, problems []*entity.ValidationConfigError) ([]*entity.ConfigFile, []*entity.ValidationConfigError) {
+	seen := make(map[string]bool, len(files))
+	valid := files[:0]
+	for _, f := range files {
+		switch {
+		case f.Name == "" || f.Name != filepath.Base(f.Name) || f.Name == "." || f.Name == "..":
+			problems = append(problems, &entity.ValidationConfigError{
+				File:    llmOutputFile,
+				Message: fmt.Sprintf("invalid file name %q: must be a plain file name without directories", f.Name),
+			})
+			continue
+		case seen[f.Name]:
+			problems = append(problems, &entity.ValidationConfigError{
+				File:    f.Name,
+				Message: fmt.Sprintf("file %s is defined more than once in LLM output", f.Name),
+			})
+			continue
+		}
+		seen[f.Name] = true
+		valid = append(valid, f)
+	}
+
+	if len(valid) == 0 && len(problems) == 0 {
+		problems = append(problems, &entity.ValidationConfigError{
+			File:    llmOutputFile,
+			Message: "no files found in LLM output",
+		})
+	}
+	return valid, problems
+}
+
+func detectFileType(fileName string) string {
+	fileName = strings.ToLower(fileName)
+
+	if strings.HasSuffix(fileName, ".tf") || strings.HasSuffix(fileName, ".tfvars") {
+		return "terraform"
+	}
+	if strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, ".yml") {
+		return "kubernetes"
+	}
+
+	return "unknown"
+}
+
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+func nameOr(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
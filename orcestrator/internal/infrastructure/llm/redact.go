@@ -0,0 +1,401 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+)
+
+// SecretPlaceholder подставляется в файлы вместо секретов: исходные значения в
+// сгенерированный код не возвращаются, их нужно передать через переменные.
+const SecretPlaceholder = entity.SecretPlaceholder
+
+// RedactionPattern — чувствительный шаблон. Restore: вернуть исходное значение в
+// сгенерированные файлы (хосты, адреса) или заменить на SecretPlaceholder (секреты).
+type RedactionPattern struct {
+	Kind    string
+	Pattern string
+	Restore bool
+}
+
+// DefaultRedactionPatterns — встроенные шаблоны ключей, токенов и адресов.
+var DefaultRedactionPatterns = []RedactionPattern{
+	{Kind: "private_key", Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{Kind: "aws_access_key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Kind: "aws_secret_key", Pattern: `(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`},
+	{Kind: "github_token", Pattern: `\bgh[pousr]_[A-Za-z0-9]{36,}\b`},
+	{Kind: "slack_token", Pattern: `\bxox[abprs]-[A-Za-z0-9-]{10,}\b`},
+	{Kind: "google_api_key", Pattern: `\bAIza[0-9A-Za-z_-]{35}\b`},
+	{Kind: "jwt", Pattern: `\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`},
+	// значение без кавычки (группа quote) в файлах — выражение HCL вроде var.x, а не секрет
+	{Kind: "password", Pattern: `(?i)(?:password|passwd|pwd|secret|token)["']?\s*[:=]\s*(?P<quote>["']?)(?P<value>[^\s"',;]{6,})`},
+	{Kind: "email", Pattern: `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`, Restore: true},
+	// диапазоны CIDR — это схема сети, а не адрес конкретного хоста, их не скрываем
+	{Kind: "ip", Pattern: `\b(?:25[0-5]|2[0-4]\d|1?\d?\d)(?:\.(?:25[0-5]|2[0-4]\d|1?\d?\d)){3}\b(?:[^/\d]|$)`, Restore: true},
+}
+
+var placeholderRe = regexp.MustCompile(`REDACTED_([A-Z0-9_]+?)_(\d+)`)
+
+// hclExpressionRe — значение без кавычек, которое является ссылкой или вызовом функции HCL:
+// var.db_password, local.x, random_password.db.result, base64decode(...).
+var hclExpressionRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*[.\[(]`)
+
+// highEntropyToken — кандидаты в секреты без известного формата.
+var highEntropyToken = regexp.MustCompile(`[A-Za-z0-9+/=_-]{24,}`)
+
+type compiledPattern struct {
+	kind    string
+	re      *regexp.Regexp
+	restore bool
+}
+
+// Redactor находит чувствительные значения и заменяет их плейсхолдерами.
+type Redactor struct {
+	patterns     []compiledPattern
+	entropyLimit float64
+}
+
+// NewRedactor компилирует встроенные и пользовательские шаблоны. entropyLimit — порог
+// энтропии Шеннона (бит на символ) для длинных токенов; 0 отключает эту проверку.
+func NewRedactor(extra []RedactionPattern, entropyLimit float64) (*Redactor, error) {
+	r := &Redactor{entropyLimit: entropyLimit}
+	// пользовательские шаблоны проверяются первыми: они точнее встроенных
+	for _, p := range append(append([]RedactionPattern{}, extra...), DefaultRedactionPatterns...) {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compile redaction pattern %s: %w", p.Kind, err)
+		}
+		kind := strings.ToLower(strings.TrimSpace(p.Kind))
+		if kind == "" {
+			kind = "custom"
+		}
+		r.patterns = append(r.patterns, compiledPattern{kind: kind, re: re, restore: p.Restore})
+	}
+	return r, nil
+}
+
+// redaction — замены одного запроса; одинаковые значения получают один плейсхолдер.
+type redaction struct {
+	byValue  map[string]string
+	original map[string]string // плейсхолдер -> исходное значение
+	restore  map[string]bool   // плейсхолдер -> возвращать ли значение в файлы
+	counts   map[string]int    // kind -> сколько значений скрыто
+}
+
+func (r *Redactor) newRedaction() *redaction {
+	return &redaction{
+		byValue:  map[string]string{},
+		original: map[string]string{},
+		restore:  map[string]bool{},
+		counts:   map[string]int{},
+	}
+}
+
+func (rd *redaction) placeholder(kind, value string, restore bool) string {
+	if ph, ok := rd.byValue[value]; ok {
+		return ph
+	}
+	rd.counts[kind]++
+	ph := fmt.Sprintf("REDACTED_%s_%d", strings.ToUpper(kind), rd.counts[kind])
+	rd.byValue[value] = ph
+	rd.original[ph] = value
+	rd.restore[ph] = restore
+	return ph
+}
+
+// redact заменяет чувствительные значения в text.
+func (r *Redactor) redact(rd *redaction, text string) string {
+	for _, p := range r.patterns {
+		text = replaceMatches(p.re, text, func(value string) string {
+			return rd.placeholder(p.kind, value, p.restore)
+		})
+	}
+	if r.entropyLimit > 0 {
+		text = highEntropyToken.ReplaceAllStringFunc(text, func(token string) string {
+			if placeholderRe.MatchString(token) || !looksLikeSecret(token, r.entropyLimit) {
+				return token
+			}
+			return rd.placeholder("secret", token, false)
+		})
+	}
+	return text
+}
+
+// replaceMatches заменяет группу value (или первую группу, если она есть) либо всё совпадение.
+// Если в шаблоне есть группа quote, выражения HCL без кавычек и интерполяции не скрываются.
+// Для шаблона ip хвостовой символ-разделитель сохраняется.
+func replaceMatches(re *regexp.Regexp, text string, replace func(string) string) string {
+	group, quote := 1, re.SubexpIndex("quote")
+	if i := re.SubexpIndex("value"); i > 0 {
+		group = i
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[0], m[1]
+		if len(m) > 2*group+1 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		value := text[start:end]
+		if quote > 0 {
+			quoted := m[2*quote+1] > m[2*quote]
+			if strings.HasPrefix(value, "${") || (!quoted && hclExpressionRe.MatchString(value)) {
+				continue
+			}
+		}
+		// отрезаем захваченный шаблоном ip разделитель
+		trimmed := strings.TrimRight(strings.TrimRightFunc(value, func(r rune) bool { return !(r == '.' || (r >= '0' && r <= '9')) }), ".")
+		if trimmed != "" && trimmed != value && isIPv4(trimmed) {
+			end = start + len(trimmed)
+			value = trimmed
+		}
+		if strings.HasPrefix(value, "REDACTED_") {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(replace(value))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func isIPv4(s string) bool {
+	return strings.Count(s, ".") == 3 && strings.Trim(s, "0123456789.") == ""
+}
+
+// restoreText возвращает в текст исходные значения несекретных плейсхолдеров,
+// а секретные заменяет на SecretPlaceholder.
+func (rd *redaction) restoreText(text string) string {
+	if len(rd.original) == 0 {
+		return text
+	}
+	return placeholderRe.ReplaceAllStringFunc(text, func(ph string) string {
+		value, ok := rd.original[ph]
+		if !ok {
+			return ph
+		}
+		if rd.restore[ph] {
+			return value
+		}
+		return SecretPlaceholder
+	})
+}
+
+// restoreCode — restoreText для файлов: секрет вне строкового литерала заменяется
+// литералом "REPLACE_ME", чтобы файл оставался корректным HCL.
+func (rd *redaction) restoreCode(text string) string {
+	if len(rd.original) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range placeholderRe.FindAllStringIndex(text, -1) {
+		ph := text[m[0]:m[1]]
+		value, ok := rd.original[ph]
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:m[0]])
+		switch {
+		case rd.restore[ph]:
+			b.WriteString(value)
+		case inStringLiteral(text[:m[0]]):
+			b.WriteString(SecretPlaceholder)
+		default:
+			b.WriteString(`"` + SecretPlaceholder + `"`)
+		}
+		last = m[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// inStringLiteral — заканчивается ли prefix внутри строки в кавычках (по числу
+// неэкранированных кавычек в последней строке).
+func inStringLiteral(prefix string) bool {
+	line := prefix[strings.LastIndexByte(prefix, '\n')+1:]
+	quotes := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			quotes++
+		}
+	}
+	return quotes%2 == 1
+}
+
+func (rd *redaction) total() int {
+	n := 0
+	for _, c := range rd.counts {
+		n += c
+	}
+	return n
+}
+
+// looksLikeSecret — длинный токен со смесью букв и цифр и высокой энтропией.
+func looksLikeSecret(token string, limit float64) bool {
+	hasDigit := strings.ContainsAny(token, "0123456789")
+	hasLetter := strings.IndexFunc(token, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }) >= 0
+	if !hasDigit || !hasLetter {
+		return false
+	}
+	return shannonEntropy(token) >= limit
+}
+
+func shannonEntropy(s string) float64 {
+	freq := make(map[rune]float64)
+	for _, r := range s {
+		freq[r]++
+	}
+	n := float64(len([]rune(s)))
+	var h float64
+	for _, c := range freq {
+		p := c / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// RedactingGenerator — внешний декоратор LLMGenerator: скрывает секреты и
+// чувствительные значения до отправки запроса (и до кэша и кассет) и
+// восстанавливает их в ответе. В лог попадают только виды и количество замен.
+// Текст промпта тоже редактируется: в него подмешиваются файлы и описания прошлых задач.
+type RedactingGenerator struct {
+	next     repository.LLMGenerator
+	redactor *Redactor
+	logger   *slog.Logger
+}
+
+func NewRedactingGenerator(next repository.LLMGenerator, redactor *Redactor, logger *slog.Logger) repository.LLMGenerator {
+	return &RedactingGenerator{next: next, redactor: redactor, logger: logger}
+}
+
+func (g *RedactingGenerator) GenerateInfrastructure(ctx context.Context, description string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	rd := g.redactor.newRedaction()
+	description = g.redactor.redact(rd, description)
+	prompt.Text = g.redactor.redact(rd, prompt.Text)
+	g.report(rd, "generate")
+
+	resp, err := g.next.GenerateInfrastructure(ctx, description, prompt)
+	if err != nil {
+		return resp, err
+	}
+	restoreFiles(rd, resp.Files)
+	return resp, nil
+}
+
+func (g *RedactingGenerator) RegenerateFileWithError(ctx context.Context, file entity.ConfigFile, errorMsg string, prompt entity.Prompt) (entity.ConfigFile, error) {
+	rd := g.redactor.newRedaction()
+	file.Content = g.redactor.redact(rd, file.Content)
+	errorMsg = g.redactor.redact(rd, errorMsg)
+	prompt.Text = g.redactor.redact(rd, prompt.Text)
+	g.report(rd, "regenerate")
+
+	fixed, err := g.next.RegenerateFileWithError(ctx, file, errorMsg, prompt)
+	if err != nil {
+		return fixed, err
+	}
+	fixed.Content = rd.restoreCode(fixed.Content)
+	return fixed, nil
+}
+
+func (g *RedactingGenerator) ClarifyRequirements(ctx context.Context, description string, prompt entity.Prompt) (entity.ClarifyResponse, error) {
+	rd := g.redactor.newRedaction()
+	description = g.redactor.redact(rd, description)
+	prompt.Text = g.redactor.redact(rd, prompt.Text)
+	g.report(rd, "clarify")
+
+	resp, err := g.next.ClarifyRequirements(ctx, description, prompt)
+	for i := range resp.Questions {
+		resp.Questions[i].Question = rd.restoreText(resp.Questions[i].Question)
+		resp.Questions[i].Default = rd.restoreText(resp.Questions[i].Default)
+	}
+	return resp, err
+}
+
+func (g *RedactingGenerator) ExtractSpec(ctx context.Context, description string, prompt entity.Prompt) (entity.SpecResponse, error) {
+	rd := g.redactor.newRedaction()
+	description = g.redactor.redact(rd, description)
+	prompt.Text = g.redactor.redact(rd, prompt.Text)
+	g.report(rd, "spec")
+
+	resp, err := g.next.ExtractSpec(ctx, description, prompt)
+	if err != nil || resp.Spec == nil || len(rd.original) == 0 {
+		return resp, err
+	}
+	// спецификация — плоские строки, проще восстановить через JSON
+	data, mErr := json.Marshal(resp.Spec)
+	if mErr != nil {
+		return resp, nil
+	}
+	var spec entity.InfraSpec
+	if uErr := json.Unmarshal([]byte(rd.restoreText(string(data))), &spec); uErr == nil {
+		resp.Spec = &spec
+	}
+	return resp, nil
+}
+
+func (g *RedactingGenerator) RefineInfrastructure(
+	ctx context.Context,
+	files []entity.ConfigFile,
+	history []entity.ChatMessage,
+	instruction string,
+	prompt entity.Prompt,
+) (entity.GenerateResponse, error) {
+	rd := g.redactor.newRedaction()
+	redactedFiles := make([]entity.ConfigFile, len(files))
+	for i, f := range files {
+		f.Content = g.redactor.redact(rd, f.Content)
+		redactedFiles[i] = f
+	}
+	redactedHistory := make([]entity.ChatMessage, len(history))
+	for i, m := range history {
+		m.Content = g.redactor.redact(rd, m.Content)
+		redactedHistory[i] = m
+	}
+	instruction = g.redactor.redact(rd, instruction)
+	prompt.Text = g.redactor.redact(rd, prompt.Text)
+	g.report(rd, "refine")
+
+	resp, err := g.next.RefineInfrastructure(ctx, redactedFiles, redactedHistory, instruction, prompt)
+	if err != nil {
+		return resp, err
+	}
+	restoreFiles(rd, resp.Files)
+	return resp, nil
+}
+
+func restoreFiles(rd *redaction, files []*entity.ConfigFile) {
+	for _, f := range files {
+		f.Content = rd.restoreCode(f.Content)
+	}
+}
+
+func (g *RedactingGenerator) report(rd *redaction, method string) {
+	if rd.total() == 0 {
+		return
+	}
+	kinds := make([]string, 0, len(rd.counts))
+	for kind, n := range rd.counts {
+		kinds = append(kinds, fmt.Sprintf("%s=%d", kind, n))
+		metrics.AddLLMRedactions(kind, n)
+	}
+	sort.Strings(kinds)
+	g.logger.Info("redacted sensitive values before llm request",
+		"method", method,
+		"total", rd.total(),
+		"kinds", strings.Join(kinds, ","),
+	)
+}
@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// echoGenerator возвращает файлы запроса как ответ модели и запоминает, что ушло в модель.
+type echoGenerator struct {
+	files       []entity.ConfigFile
+	instruction string
+	prompt      string
+}
+
+func (g *echoGenerator) GenerateInfrastructure(_ context.Context, description string, prompt entity.Prompt) (entity.GenerateResponse, error) {
+	g.prompt = prompt.Text
+	return entity.GenerateResponse{Files: []*entity.ConfigFile{{Name: "main.tf", Content: description}}}, nil
+}
+
+func (g *echoGenerator) RegenerateFileWithError(_ context.Context, file entity.ConfigFile, _ string, _ entity.Prompt) (entity.ConfigFile, error) {
+	return file, nil
+}
+
+func (g *echoGenerator) ClarifyRequirements(context.Context, string, entity.Prompt) (entity.ClarifyResponse, error) {
+	return entity.ClarifyResponse{}, nil
+}
+
+func (g *echoGenerator) ExtractSpec(context.Context, string, entity.Prompt) (entity.SpecResponse, error) {
+	return entity.SpecResponse{}, nil
+}
+
+func (g *echoGenerator) RefineInfrastructure(
+	_ context.Context,
+	files []entity.ConfigFile,
+	_ []entity.ChatMessage,
+	instruction string,
+	prompt entity.Prompt,
+) (entity.GenerateResponse, error) {
+	g.files, g.instruction, g.prompt = files, instruction, prompt.Text
+	resp := entity.GenerateResponse{}
+	for _, f := range files {
+		f := f
+		resp.Files = append(resp.Files, &f)
+	}
+	return resp, nil
+}
+
+func newTestRedacting(t *testing.T) (*echoGenerator, *RedactingGenerator) {
+	t.Helper()
+	redactor, err := NewRedactor(nil, 0)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	echo := &echoGenerator{}
+	return echo, NewRedactingGenerator(echo, redactor, slog.New(slog.NewTextHandler(io.Discard, nil))).(*RedactingGenerator)
+}
+
+func TestRedactingRefineRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		sent    string // подстрока, которая должна уйти в модель как есть; пусто — не проверяется
+		hidden  string // значение, которое не должно уйти в модель
+		want    string // файл после восстановления
+	}{
+		{
+			name:    "variable reference",
+			content: "password = var.db_password\n",
+			sent:    "password = var.db_password",
+			want:    "password = var.db_password\n",
+		},
+		{
+			name:    "resource attribute",
+			content: "password = random_password.db.result\n",
+			sent:    "password = random_password.db.result",
+			want:    "password = random_password.db.result\n",
+		},
+		{
+			name:    "function call",
+			content: "token = base64decode(local.token)\n",
+			sent:    "token = base64decode(local.token)",
+			want:    "token = base64decode(local.token)\n",
+		},
+		{
+			name:    "interpolation",
+			content: "password = \"${var.db_password}\"\n",
+			sent:    "${var.db_password}",
+			want:    "password = \"${var.db_password}\"\n",
+		},
+		{
+			name:    "secret literal",
+			content: "password = \"s3cr3t-Pa55\"\n",
+			hidden:  "s3cr3t-Pa55",
+			want:    "password = \"REPLACE_ME\"\n",
+		},
+		{
+			name:    "host address is restored",
+			content: "host = \"10.20.30.40\"\n",
+			hidden:  "10.20.30.40",
+			want:    "host = \"10.20.30.40\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			echo, g := newTestRedacting(t)
+			resp, err := g.RefineInfrastructure(context.Background(),
+				[]entity.ConfigFile{{Name: "main.tf", Content: tt.content}}, nil, "add tags", entity.Prompt{})
+			if err != nil {
+				t.Fatalf("RefineInfrastructure: %v", err)
+			}
+
+			sent := echo.files[0].Content
+			if tt.sent != "" && !strings.Contains(sent, tt.sent) {
+				t.Errorf("sent %q, want it to contain %q", sent, tt.sent)
+			}
+			if tt.hidden != "" && strings.Contains(sent, tt.hidden) {
+				t.Errorf("sent %q leaks %q", sent, tt.hidden)
+			}
+			if got := resp.Files[0].Content; got != tt.want {
+				t.Errorf("restored %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreCodeQuotesBareSecret(t *testing.T) {
+	redactor, err := NewRedactor(nil, 0)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	rd := redactor.newRedaction()
+	redacted := redactor.redact(rd, `password = "hunter2hunter2"`)
+	ph := strings.TrimSuffix(strings.TrimPrefix(redacted, `password = "`), `"`)
+
+	// модель может вернуть плейсхолдер без кавычек или внутри строки
+	tests := map[string]string{
+		"password = " + ph + "\n":                           "password = \"REPLACE_ME\"\n",
+		"url = \"postgres://app:" + ph + "@db:5432/app\"\n": "url = \"postgres://app:REPLACE_ME@db:5432/app\"\n",
+	}
+	for model, want := range tests {
+		if got := rd.restoreCode(model); got != want {
+			t.Errorf("restoreCode(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
+func TestRedactingPromptText(t *testing.T) {
+	echo, g := newTestRedacting(t)
+	prompt := entity.Prompt{Text: "Example from a past job: password = \"past-S3cret\", host 192.168.10.5"}
+	if _, err := g.GenerateInfrastructure(context.Background(), "an s3 bucket", prompt); err != nil {
+		t.Fatalf("GenerateInfrastructure: %v", err)
+	}
+	for _, leaked := range []string{"past-S3cret", "192.168.10.5"} {
+		if strings.Contains(echo.prompt, leaked) {
+			t.Errorf("prompt sent to the model leaks %q: %q", leaked, echo.prompt)
+		}
+	}
+}
+
+func TestRedactFreeTextPassword(t *testing.T) {
+	redactor, err := NewRedactor(nil, 0)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	rd := redactor.newRedaction()
+	got := redactor.redact(rd, "create a database, admin password: hunter2xyz")
+	if strings.Contains(got, "hunter2xyz") {
+		t.Errorf("redact left the password in %q", got)
+	}
+}
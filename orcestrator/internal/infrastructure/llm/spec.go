@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+)
+
+const specPrompt = "Do not write any code yet. Turn the infrastructure request below into a structured specification. " +
+	"Reply with JSON only, in this shape:\n" +
+	`{"cloud": "aws", "region": "eu-central-1",` +
+	` "components": [{"name": "web", "kind": "vm", "size": "t3.small", "count": 2, "subnet": "private-a", "public_ip": false}],` +
+	` "network": {"cidr": "10.0.0.0/16", "subnets": [{"name": "private-a", "cidr": "10.0.1.0/24", "public": false}]},` +
+	` "security": {"encrypt_at_rest": true, "allowed_ingress_cidrs": ["10.0.0.0/8"], "notes": []}}` +
+	"\nUse component names that are valid Terraform resource names. " +
+	"Leave a field empty instead of inventing a value the request does not imply.\n\nRequest: "
+
+func (g *AmveraGenerator) ExtractSpec(ctx context.Context, description string, prompt entity.Prompt) (entity.SpecResponse, error) {
+	messages := []map[string]string{
+		{"role": "system", "content": prompt.Text},
+		{"role": "user", "content": specPrompt + description},
+	}
+
+	content, _, usage, err := g.chat(ctx, messages, g.verbosity)
+	if err != nil {
+		return entity.SpecResponse{}, err
+	}
+
+	resp := entity.SpecResponse{Model: modelFor(ctx, g.model), Usage: usage}
+	spec, err := parseSpec(content)
+	if err != nil {
+		return resp, err
+	}
+	resp.Spec = spec
+	return resp, nil
+}
+
+func parseSpec(content string) (*entity.InfraSpec, error) {
+	var spec entity.InfraSpec
+	if err := json.Unmarshal([]byte(unfenceJSON(content)), &spec); err != nil {
+		return nil, fmt.Errorf("spec is not valid JSON: %w", err)
+	}
+	spec.Cloud = strings.ToLower(strings.TrimSpace(spec.Cloud))
+	spec.Region = strings.TrimSpace(spec.Region)
+	return &spec, nil
+}
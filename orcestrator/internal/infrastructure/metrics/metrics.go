@@ -77,6 +77,35 @@ var (
 		},
 		[]string{"model"},
 	)
+	LLMTokens = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "llmgen_llm_tokens_total",
+			Help: "Number of LLM tokens consumed by model and kind",
+		},
+		[]string{"model", "kind"}, // kind: prompt|completion
+	)
+	LLMContinuations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "llmgen_llm_continuations_total",
+			Help: "Number of continuation requests sent for truncated LLM output",
+		},
+		[]string{"model"},
+	)
+	LLMCache = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "llmgen_llm_cache_total",
+			Help: "LLM response cache lookups by result",
+		},
+		[]string{"result"}, // result: hit|miss
+	)
+
+	LLMRedactions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "llmgen_llm_redactions_total",
+			Help: "Sensitive values redacted from LLM requests by kind",
+		},
+		[]string{"kind"},
+	)
 
 	// DB / file storage ops
 	DBFileOps = prometheus.NewCounterVec(
@@ -120,6 +149,10 @@ func init() {
 		DeployConfirms,
 		// LLM / code
 		LLMRequests,
+		LLMTokens,
+		LLMContinuations,
+		LLMCache,
+		LLMRedactions,
 
 		// DB
 		DBFileOps,
@@ -175,6 +208,23 @@ func IncLLMRequest(model string) {
 	LLMRequests.WithLabelValues(model).Inc()
 }
 
+func AddLLMTokens(model string, prompt, completion int64) {
+	LLMTokens.WithLabelValues(model, "prompt").Add(float64(prompt))
+	LLMTokens.WithLabelValues(model, "completion").Add(float64(completion))
+}
+
+func IncLLMContinuation(model string) {
+	LLMContinuations.WithLabelValues(model).Inc()
+}
+
+func IncLLMCache(result string) {
+	LLMCache.WithLabelValues(result).Inc()
+}
+
+func AddLLMRedactions(kind string, n int) {
+	LLMRedactions.WithLabelValues(kind).Add(float64(n))
+}
+
 // DB / file ops
 func IncDBFileOp(op string) {
 	DBFileOps.WithLabelValues(op).Inc()
@@ -70,6 +70,28 @@ func (r *FileRepository) SaveFiles(ctx context.Context, files []*entity.ConfigFi
 	return nil
 }
 
+// ReplaceFiles делает набор файлов в каталоге задачи равным files: файлы прежнего
+// набора (по metadata.json), которых нет в новом, удаляются. Артефакты валидаторов
+// и деплоя в подкаталогах не трогаются.
+func (r *FileRepository) ReplaceFiles(ctx context.Context, files []*entity.ConfigFile, requestID string) error {
+	previous, err := r.GetFiles(ctx, requestID)
+	if err == nil {
+		keep := make(map[string]bool, len(files))
+		for _, f := range files {
+			keep[f.Name] = true
+		}
+		for _, f := range previous {
+			if keep[f.Name] || f.Name != filepath.Base(f.Name) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(r.basePath, requestID, f.Name)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove stale file %s: %w", f.Name, err)
+			}
+		}
+	}
+	return r.SaveFiles(ctx, files, requestID)
+}
+
 func (r *FileRepository) GetFiles(ctx context.Context, requestID string) ([]*entity.ConfigFile, error) {
 	metadataPath := filepath.Join(r.basePath, requestID, "metadata.json")
 
@@ -139,3 +161,60 @@ func (r *FileRepository) DeleteRequest(ctx context.Context, requestID string) er
 func (r *FileRepository) GetFilesByJobID(ctx context.Context, jobID string) ([]*entity.ConfigFile, error) {
 	return []*entity.ConfigFile{}, fmt.Errorf("FileRepository does not support GetFilesByJobID functionality")
 }
+
+// inputsFile — значения обязательных входных переменных задачи. terraform сам подхватывает
+// *.auto.tfvars.json; в metadata.json файл не попадает и при смене ревизии не удаляется.
+const inputsFile = "orchestrator.auto.tfvars.json"
+
+// SaveInputs добавляет значения входных переменных к уже переданным для задачи.
+func (r *FileRepository) SaveInputs(ctx context.Context, requestID string, values map[string]string) error {
+	current, err := r.readInputs(requestID)
+	if err != nil {
+		return err
+	}
+	for name, value := range values {
+		current[name] = value
+	}
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal inputs: %w", err)
+	}
+	requestDir := filepath.Join(r.basePath, requestID)
+	if err := os.MkdirAll(requestDir, 0766); err != nil {
+		return fmt.Errorf("failed to create request directory: %w", err)
+	}
+	// значения могут быть секретами — файл доступен только владельцу
+	if err := os.WriteFile(filepath.Join(requestDir, inputsFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write inputs: %w", err)
+	}
+	return nil
+}
+
+// InputNames — имена входных переменных, значения которых уже переданы.
+func (r *FileRepository) InputNames(ctx context.Context, requestID string) (map[string]bool, error) {
+	current, err := r.readInputs(requestID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(current))
+	for name := range current {
+		names[name] = true
+	}
+	return names, nil
+}
+
+func (r *FileRepository) readInputs(requestID string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(r.basePath, requestID, inputsFile))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inputs: %w", err)
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal inputs: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,79 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// LLMCacheRepository — файловый кэш ответов LLM: один JSON файл на ключ.
+type LLMCacheRepository struct {
+	dir string
+}
+
+type llmCacheEntry struct {
+	Key       string                  `json:"key"`
+	ExpiresAt time.Time               `json:"expires_at"`
+	Response  entity.GenerateResponse `json:"response"`
+}
+
+func NewLLMCacheRepository(dir string) (*LLMCacheRepository, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+	return &LLMCacheRepository{dir: dir}, nil
+}
+
+func (r *LLMCacheRepository) Get(ctx context.Context, key string) (*entity.GenerateResponse, error) {
+	path := r.path(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry llmCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cache entry: %w", err)
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		_ = os.Remove(path)
+		return nil, nil
+	}
+
+	return &entry.Response, nil
+}
+
+func (r *LLMCacheRepository) Put(ctx context.Context, key string, resp entity.GenerateResponse, ttl time.Duration) error {
+	data, err := json.MarshalIndent(llmCacheEntry{
+		Key:       key,
+		ExpiresAt: time.Now().Add(ttl),
+		Response:  resp,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	// пишем во временный файл и переименовываем, чтобы не оставить обрезанную запись
+	tmp := r.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, r.path(key)); err != nil {
+		return fmt.Errorf("failed to commit cache entry: %w", err)
+	}
+	return nil
+}
+
+func (r *LLMCacheRepository) path(key string) string {
+	return filepath.Join(r.dir, key+".json")
+}
@@ -0,0 +1,253 @@
+package filesystem
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+)
+
+// maxExampleLen — пример длиннее обрезается, чтобы не раздувать контекст генерации.
+const maxExampleLen = 2000
+
+// ModuleCatalogRepository читает модули из локального каталога: каждая директория с *.tf
+// файлами — модуль, его source — sourcePrefix + относительный путь. Если задан gitURL,
+// перед чтением каталог клонируется или обновляется до ref.
+type ModuleCatalogRepository struct {
+	dir          string
+	sourcePrefix string
+	gitURL       string
+	gitRef       string
+}
+
+func NewModuleCatalogRepository(dir, sourcePrefix, gitURL, gitRef string) repository.ModuleCatalogRepository {
+	return &ModuleCatalogRepository{
+		dir:          dir,
+		sourcePrefix: strings.TrimSuffix(sourcePrefix, "/"),
+		gitURL:       gitURL,
+		gitRef:       gitRef,
+	}
+}
+
+func (r *ModuleCatalogRepository) Load(ctx context.Context) ([]*entity.Module, error) {
+	if r.gitURL != "" {
+		if err := r.sync(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var modules []*entity.Module
+	err := filepath.WalkDir(r.dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if p != r.dir && (strings.HasPrefix(name, ".") || name == "examples") {
+			return filepath.SkipDir
+		}
+		module, err := r.readModule(p)
+		if err != nil {
+			return err
+		}
+		if module != nil {
+			modules = append(modules, module)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module catalog %s: %w", r.dir, err)
+	}
+	return modules, nil
+}
+
+// sync клонирует репозиторий каталога или подтягивает последнюю версию ref.
+func (r *ModuleCatalogRepository) sync(ctx context.Context) error {
+	var cmds [][]string
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err == nil {
+		ref := r.gitRef
+		if ref == "" {
+			ref = "HEAD"
+		}
+		cmds = [][]string{
+			{"git", "-C", r.dir, "fetch", "--depth", "1", "origin", ref},
+			{"git", "-C", r.dir, "reset", "--hard", "FETCH_HEAD"},
+		}
+	} else {
+		clone := []string{"git", "clone", "--depth", "1"}
+		if r.gitRef != "" {
+			clone = append(clone, "--branch", r.gitRef)
+		}
+		cmds = [][]string{append(clone, r.gitURL, r.dir)}
+	}
+
+	for _, args := range cmds {
+		out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s: %w: %s", strings.Join(args[:3], " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// readModule собирает модуль из директории; nil — в директории нет *.tf файлов.
+func (r *ModuleCatalogRepository) readModule(dir string) (*entity.Module, error) {
+	tfFiles, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(tfFiles) == 0 {
+		return nil, err
+	}
+	sort.Strings(tfFiles)
+
+	rel, err := filepath.Rel(r.dir, dir)
+	if err != nil {
+		return nil, err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = path.Base(filepath.ToSlash(r.dir))
+	}
+	module := &entity.Module{
+		Name:   rel,
+		Source: path.Join(r.sourcePrefix, rel),
+	}
+
+	parser := hclparse.NewParser()
+	for _, f := range tfFiles {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		if module.Description == "" {
+			module.Description = leadingComment(string(src))
+		}
+		hclFile, diags := parser.ParseHCL(src, f)
+		if diags.HasErrors() {
+			// модуль с ошибками всё равно попадает в каталог — без входов и выходов
+			continue
+		}
+		body, ok := hclFile.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, b := range body.Blocks {
+			if len(b.Labels) != 1 {
+				continue
+			}
+			switch b.Type {
+			case "variable":
+				module.Inputs = append(module.Inputs, moduleInput(b, src))
+			case "output":
+				module.Outputs = append(module.Outputs, b.Labels[0])
+			}
+		}
+	}
+
+	readme, _ := os.ReadFile(filepath.Join(dir, "README.md"))
+	if desc := readmeDescription(string(readme)); desc != "" {
+		module.Description = desc
+	}
+	module.Example = moduleExample(dir, string(readme))
+
+	return module, nil
+}
+
+func moduleInput(b *hclsyntax.Block, src []byte) entity.ModuleInput {
+	input := entity.ModuleInput{Name: b.Labels[0], Required: true}
+	if attr, ok := b.Body.Attributes["type"]; ok {
+		input.Type = string(attr.Expr.Range().SliceBytes(src))
+	}
+	if attr, ok := b.Body.Attributes["description"]; ok {
+		if v, diags := attr.Expr.Value(nil); !diags.HasErrors() && v.Type().FriendlyName() == "string" {
+			input.Description = v.AsString()
+		}
+	}
+	if _, ok := b.Body.Attributes["default"]; ok {
+		input.Required = false
+	}
+	return input
+}
+
+// leadingComment — комментарий в начале файла, которым модуль часто описывают вместо README.
+func leadingComment(src string) string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#"):
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+		case strings.HasPrefix(line, "//"):
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+		default:
+			return strings.TrimSpace(strings.Join(lines, " "))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, " "))
+}
+
+// readmeDescription — первый абзац README, не считая заголовков.
+func readmeDescription(readme string) string {
+	var para []string
+	for _, line := range strings.Split(readme, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "```"):
+			if len(para) > 0 {
+				return strings.Join(para, " ")
+			}
+		case line == "":
+			if len(para) > 0 {
+				return strings.Join(para, " ")
+			}
+		default:
+			para = append(para, line)
+		}
+	}
+	return strings.Join(para, " ")
+}
+
+// moduleExample берёт пример вызова из examples/ или из блока кода README с module "...".
+func moduleExample(dir, readme string) string {
+	examples, _ := filepath.Glob(filepath.Join(dir, "examples", "*", "*.tf"))
+	more, _ := filepath.Glob(filepath.Join(dir, "examples", "*.tf"))
+	examples = append(more, examples...)
+	sort.Strings(examples)
+	for _, f := range examples {
+		src, err := os.ReadFile(f)
+		if err == nil && strings.Contains(string(src), "module ") {
+			return truncateExample(string(src))
+		}
+	}
+
+	// нечётные части — содержимое блоков кода
+	parts := strings.Split(readme, "```")
+	for i := 1; i < len(parts); i += 2 {
+		lang, body, _ := strings.Cut(parts[i], "\n")
+		lang = strings.TrimSpace(lang)
+		if (lang == "hcl" || lang == "terraform" || lang == "tf") && strings.Contains(body, "module ") {
+			return truncateExample(body)
+		}
+	}
+	return ""
+}
+
+func truncateExample(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > maxExampleLen {
+		s = s[:maxExampleLen] + "\n# ..."
+	}
+	return s
+}
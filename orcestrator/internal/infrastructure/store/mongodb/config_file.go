@@ -21,6 +21,7 @@ func NewMongoConfigRepo(db *mongo.Database) repository.ConfgiFileRepository {
 
 	_, _ = col.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
 		{Keys: bson.D{bson.E{Key: "jobid", Value: 1}}},
+		{Keys: bson.D{{Key: "jobid", Value: 1}, {Key: "revision", Value: 1}}},
 	})
 
 	return &MongoConfigRepo{
@@ -72,6 +73,22 @@ func (r *MongoConfigRepo) GetFilesByJobID(ctx context.Context, jobID string) ([]
 	return files, nil
 }
 
+func (r *MongoConfigRepo) GetFilesByRevision(ctx context.Context, jobID string, revision int) ([]*entity.ConfigFile, error) {
+	metrics.IncDBFileOp("get")
+
+	filter := bson.M{"jobid": jobID, "revision": revision}
+	if revision == 0 {
+		// файлы, сохранённые до появления ревизий, поля revision не имеют
+		filter["revision"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	files, err := r.findFiles(ctx, filter)
+	if err != nil {
+		metrics.IncError("mongo_config_repo", "get_by_revision_error")
+		return nil, err
+	}
+	return files, nil
+}
+
 func (r *MongoConfigRepo) ListRequests(ctx context.Context) ([]string, error) {
 	metrics.IncDBFileOp("list")
 
@@ -0,0 +1,109 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoExampleRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoExampleRepo(db *mongo.Database) repository.ExampleRepository {
+	col := db.Collection("examples")
+
+	_, _ = col.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{bson.E{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{bson.E{Key: "target", Value: 1}}},
+	})
+
+	return &MongoExampleRepo{
+		col: col,
+	}
+}
+
+func (r *MongoExampleRepo) Upsert(ctx context.Context, example *entity.Example) error {
+	metrics.IncDBFileOp("put")
+
+	now := time.Now()
+	if example.CreatedAt.IsZero() {
+		example.CreatedAt = now
+	}
+	example.UpdatedAt = now
+	_, err := r.col.ReplaceOne(ctx, bson.M{"id": example.ID}, example, options.Replace().SetUpsert(true))
+	if err != nil {
+		metrics.IncError("mongo_example_repo", "upsert_error")
+		return err
+	}
+	return nil
+}
+
+func (r *MongoExampleRepo) GetByID(ctx context.Context, id string) (*entity.Example, error) {
+	metrics.IncDBFileOp("get")
+
+	var example entity.Example
+	err := r.col.FindOne(ctx, bson.M{"id": id}).Decode(&example)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		metrics.IncError("mongo_example_repo", "get_error")
+		return nil, err
+	}
+	return &example, nil
+}
+
+func (r *MongoExampleRepo) ListByTarget(ctx context.Context, target string) ([]*entity.Example, error) {
+	metrics.IncDBFileOp("list")
+
+	filter := bson.M{}
+	if target != "" {
+		filter["target"] = target
+	}
+	cur, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdat", Value: -1}}))
+	if err != nil {
+		metrics.IncError("mongo_example_repo", "list_error")
+		return nil, err
+	}
+	defer func() {
+		err := cur.Close(ctx)
+		if err != nil {
+			log.Printf("close body err: %s", err)
+		}
+	}()
+
+	var examples []*entity.Example
+	for cur.Next(ctx) {
+		var e entity.Example
+		if err := cur.Decode(&e); err != nil {
+			metrics.IncError("mongo_example_repo", "list_decode_error")
+			return nil, err
+		}
+		examples = append(examples, &e)
+	}
+	return examples, cur.Err()
+}
+
+func (r *MongoExampleRepo) Delete(ctx context.Context, id string) error {
+	metrics.IncDBFileOp("delete")
+
+	res, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		metrics.IncError("mongo_example_repo", "delete_error")
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoExperimentRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoExperimentRepo(db *mongo.Database) repository.ExperimentRepository {
+	col := db.Collection("experiments")
+
+	_, _ = col.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{bson.E{Key: "id", Value: 1}}},
+		{Keys: bson.D{{Key: "target", Value: 1}, {Key: "active", Value: 1}}},
+	})
+
+	return &MongoExperimentRepo{
+		col: col,
+	}
+}
+
+func (r *MongoExperimentRepo) Create(ctx context.Context, exp *entity.Experiment) error {
+	metrics.IncDBFileOp("put")
+
+	exp.CreatedAt = time.Now()
+	exp.UpdatedAt = time.Now()
+	if _, err := r.col.InsertOne(ctx, exp); err != nil {
+		metrics.IncError("mongo_experiment_repo", "create_error")
+		return err
+	}
+	return nil
+}
+
+func (r *MongoExperimentRepo) GetByID(ctx context.Context, id string) (*entity.Experiment, error) {
+	metrics.IncDBFileOp("get")
+
+	return r.findOne(ctx, bson.M{"id": id})
+}
+
+func (r *MongoExperimentRepo) ActiveForTarget(ctx context.Context, target string) (*entity.Experiment, error) {
+	metrics.IncDBFileOp("get")
+
+	return r.findOne(ctx, bson.M{"target": target, "active": true})
+}
+
+func (r *MongoExperimentRepo) List(ctx context.Context) ([]*entity.Experiment, error) {
+	metrics.IncDBFileOp("list")
+
+	cur, err := r.col.Find(ctx, bson.D{})
+	if err != nil {
+		metrics.IncError("mongo_experiment_repo", "list_error")
+		return nil, err
+	}
+	defer func() {
+		err := cur.Close(ctx)
+		if err != nil {
+			log.Printf("close body err: %s", err)
+		}
+	}()
+
+	var exps []*entity.Experiment
+	for cur.Next(ctx) {
+		var e entity.Experiment
+		if err := cur.Decode(&e); err != nil {
+			metrics.IncError("mongo_experiment_repo", "list_decode_error")
+			return nil, err
+		}
+		exps = append(exps, &e)
+	}
+	return exps, cur.Err()
+}
+
+func (r *MongoExperimentRepo) Update(ctx context.Context, exp *entity.Experiment) error {
+	metrics.IncDBFileOp("put")
+
+	exp.UpdatedAt = time.Now()
+	res, err := r.col.ReplaceOne(ctx, bson.M{"id": exp.ID}, exp)
+	if err != nil {
+		metrics.IncError("mongo_experiment_repo", "update_error")
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoExperimentRepo) Delete(ctx context.Context, id string) error {
+	metrics.IncDBFileOp("delete")
+
+	res, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		metrics.IncError("mongo_experiment_repo", "delete_error")
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoExperimentRepo) findOne(ctx context.Context, filter bson.M) (*entity.Experiment, error) {
+	var exp entity.Experiment
+	err := r.col.FindOne(ctx, filter).Decode(&exp)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		metrics.IncError("mongo_experiment_repo", "get_error")
+		return nil, err
+	}
+	return &exp, nil
+}
@@ -118,6 +118,33 @@ func (r *MongoJobRepo) ListByStatus(ctx context.Context, status entity.JobStatus
 	return jobs, cur.Err()
 }
 
+func (r *MongoJobRepo) ListByExperiment(ctx context.Context, experimentID string) ([]*entity.Job, error) {
+	metrics.IncDBFileOp("list")
+
+	cur, err := r.jobsCol.Find(ctx, bson.M{"experimentid": experimentID})
+	if err != nil {
+		metrics.IncError("mongo_job_repo", "list_by_experiment_error")
+		return nil, err
+	}
+	defer func() {
+		err := cur.Close(ctx)
+		if err != nil {
+			log.Printf("close body err: %s", err)
+		}
+	}()
+
+	var jobs []*entity.Job
+	for cur.Next(ctx) {
+		var j entity.Job
+		if err := cur.Decode(&j); err != nil {
+			metrics.IncError("mongo_job_repo", "list_by_experiment_decode_error")
+			return nil, err
+		}
+		jobs = append(jobs, &j)
+	}
+	return jobs, cur.Err()
+}
+
 func (r *MongoJobRepo) Update(ctx context.Context, job *entity.Job) error {
 	metrics.IncDBFileOp("put")
 
@@ -0,0 +1,70 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoLLMCacheRepo struct {
+	col *mongo.Collection
+}
+
+type llmCacheDoc struct {
+	Key       string                  `bson:"key"`
+	Response  entity.GenerateResponse `bson:"response"`
+	ExpiresAt time.Time               `bson:"expires_at"`
+}
+
+func NewMongoLLMCacheRepo(db *mongo.Database) repository.LLMCacheRepository {
+	col := db.Collection("llm_cache")
+
+	_, _ = col.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{bson.E{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// Mongo сам удаляет просроченные записи
+		{Keys: bson.D{bson.E{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	return &MongoLLMCacheRepo{
+		col: col,
+	}
+}
+
+func (r *MongoLLMCacheRepo) Get(ctx context.Context, key string) (*entity.GenerateResponse, error) {
+	metrics.IncDBFileOp("get")
+
+	var doc llmCacheDoc
+	err := r.col.FindOne(ctx, bson.M{"key": key, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		metrics.IncError("mongo_llm_cache_repo", "get_error")
+		return nil, err
+	}
+	return &doc.Response, nil
+}
+
+func (r *MongoLLMCacheRepo) Put(ctx context.Context, key string, resp entity.GenerateResponse, ttl time.Duration) error {
+	metrics.IncDBFileOp("put")
+
+	doc := llmCacheDoc{
+		Key:       key,
+		Response:  resp,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	_, err := r.col.ReplaceOne(ctx, bson.M{"key": key}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		metrics.IncError("mongo_llm_cache_repo", "put_error")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoPromptRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoPromptRepo(db *mongo.Database) repository.PromptRepository {
+	col := db.Collection("prompts")
+
+	_, _ = col.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &MongoPromptRepo{
+		col: col,
+	}
+}
+
+func (r *MongoPromptRepo) Create(ctx context.Context, prompt *entity.Prompt) error {
+	metrics.IncDBFileOp("put")
+
+	if prompt.CreatedAt.IsZero() {
+		prompt.CreatedAt = time.Now()
+	}
+	if _, err := r.col.InsertOne(ctx, prompt); err != nil {
+		metrics.IncError("mongo_prompt_repo", "create_error")
+		return err
+	}
+	return nil
+}
+
+func (r *MongoPromptRepo) Get(ctx context.Context, id string, version int) (*entity.Prompt, error) {
+	metrics.IncDBFileOp("get")
+
+	return r.findOne(ctx, bson.M{"id": id, "version": version})
+}
+
+func (r *MongoPromptRepo) Latest(ctx context.Context, id string) (*entity.Prompt, error) {
+	metrics.IncDBFileOp("get")
+
+	return r.findOne(ctx, bson.M{"id": id}, options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}))
+}
+
+func (r *MongoPromptRepo) List(ctx context.Context) ([]*entity.Prompt, error) {
+	metrics.IncDBFileOp("list")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "id", Value: 1}, {Key: "version", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$id", "doc": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "id", Value: 1}}}},
+	}
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		metrics.IncError("mongo_prompt_repo", "list_error")
+		return nil, err
+	}
+	return r.decodeAll(ctx, cur)
+}
+
+func (r *MongoPromptRepo) ListVersions(ctx context.Context, id string) ([]*entity.Prompt, error) {
+	metrics.IncDBFileOp("list")
+
+	cur, err := r.col.Find(ctx, bson.M{"id": id}, options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		metrics.IncError("mongo_prompt_repo", "list_versions_error")
+		return nil, err
+	}
+	return r.decodeAll(ctx, cur)
+}
+
+func (r *MongoPromptRepo) Delete(ctx context.Context, id string, version int) error {
+	metrics.IncDBFileOp("delete")
+
+	res, err := r.col.DeleteOne(ctx, bson.M{"id": id, "version": version})
+	if err != nil {
+		metrics.IncError("mongo_prompt_repo", "delete_error")
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoPromptRepo) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*entity.Prompt, error) {
+	var prompt entity.Prompt
+	err := r.col.FindOne(ctx, filter, opts...).Decode(&prompt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		metrics.IncError("mongo_prompt_repo", "get_error")
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+func (r *MongoPromptRepo) decodeAll(ctx context.Context, cur *mongo.Cursor) ([]*entity.Prompt, error) {
+	defer func() {
+		err := cur.Close(ctx)
+		if err != nil {
+			log.Printf("close body err: %s", err)
+		}
+	}()
+
+	var prompts []*entity.Prompt
+	for cur.Next(ctx) {
+		var p entity.Prompt
+		if err := cur.Decode(&p); err != nil {
+			metrics.IncError("mongo_prompt_repo", "decode_error")
+			return nil, err
+		}
+		prompts = append(prompts, &p)
+	}
+	return prompts, cur.Err()
+}
@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoUsageRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoUsageRepo(db *mongo.Database) repository.UsageRepository {
+	col := db.Collection("llm_usage")
+
+	_, _ = col.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "owner", Value: 1}, {Key: "createdat", Value: 1}},
+	})
+
+	return &MongoUsageRepo{
+		col: col,
+	}
+}
+
+func (r *MongoUsageRepo) Record(ctx context.Context, rec *entity.UsageRecord) error {
+	metrics.IncDBFileOp("put")
+
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	if _, err := r.col.InsertOne(ctx, rec); err != nil {
+		metrics.IncError("mongo_usage_repo", "record_error")
+		return err
+	}
+	return nil
+}
+
+func (r *MongoUsageRepo) Sum(ctx context.Context, owner string, from, to time.Time) (entity.UsageTotals, error) {
+	metrics.IncDBFileOp("get")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"owner":     owner,
+			"createdat": bson.M{"$gte": from, "$lt": to},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    nil,
+			"tokens": bson.M{"$sum": "$usage.totaltokens"},
+			"cost":   bson.M{"$sum": "$cost"},
+		}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		metrics.IncError("mongo_usage_repo", "sum_error")
+		return entity.UsageTotals{}, err
+	}
+	defer func() {
+		err := cur.Close(ctx)
+		if err != nil {
+			log.Printf("close body err: %s", err)
+		}
+	}()
+
+	var totals entity.UsageTotals
+	if cur.Next(ctx) {
+		var doc struct {
+			Tokens int64   `bson:"tokens"`
+			Cost   float64 `bson:"cost"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			metrics.IncError("mongo_usage_repo", "sum_decode_error")
+			return entity.UsageTotals{}, err
+		}
+		totals = entity.UsageTotals{Tokens: doc.Tokens, Cost: doc.Cost}
+	}
+	return totals, cur.Err()
+}
@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/domain/repository"
+	"orchestrator/internal/infrastructure/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoValidationReportRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoValidationReportRepo(db *mongo.Database) repository.ValidationReportRepository {
+	col := db.Collection("validation_reports")
+
+	_, _ = col.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "jobid", Value: 1}, {Key: "revision", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	return &MongoValidationReportRepo{
+		col: col,
+	}
+}
+
+func (r *MongoValidationReportRepo) Save(ctx context.Context, report *entity.ValidationReport) error {
+	metrics.IncDBFileOp("put")
+
+	filter := bson.M{"jobid": report.JobID, "revision": report.Revision}
+	_, err := r.col.ReplaceOne(ctx, filter, report, options.Replace().SetUpsert(true))
+	if err != nil {
+		metrics.IncError("mongo_validation_repo", "save_error")
+		return err
+	}
+	return nil
+}
+
+func (r *MongoValidationReportRepo) Get(ctx context.Context, jobID string, revision int) (*entity.ValidationReport, error) {
+	metrics.IncDBFileOp("get")
+
+	var report entity.ValidationReport
+	err := r.col.FindOne(ctx, bson.M{"jobid": jobID, "revision": revision}).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		metrics.IncError("mongo_validation_repo", "get_error")
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *MongoValidationReportRepo) DeleteByJob(ctx context.Context, jobID string) error {
+	metrics.IncDBFileOp("delete")
+
+	if _, err := r.col.DeleteMany(ctx, bson.M{"jobid": jobID}); err != nil {
+		metrics.IncError("mongo_validation_repo", "delete_error")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"orchestrator/app/usecase"
+	"orchestrator/internal/domain/entity"
+)
+
+type rateJobReq struct {
+	Rating int `json:"rating"`
+}
+
+type editFilesReq struct {
+	Files []entity.ConfigFile `json:"files"`
+}
+
+// GET /api/v1/datasets/export?target=&from=&to=&min_rating=&validated=&edited=&metadata=
+// Отдаёт JSONL в формате chat completion.
+func (h *OrchestratorHandler) handleExportDataset(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseDatasetFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="dataset.jsonl"`)
+	n, err := h.datasetService.Export(r.Context(), filter, w)
+	if err != nil {
+		// часть строк могла уже уйти клиенту — статус менять поздно
+		h.logger.Error("export dataset failed", "records", n, "err", err)
+		return
+	}
+	h.logger.Info("dataset exported", "records", n)
+}
+
+func parseDatasetFilter(r *http.Request) (entity.DatasetFilter, error) {
+	q := r.URL.Query()
+	filter := entity.DatasetFilter{Target: q.Get("target")}
+
+	var err error
+	if filter.From, err = parseDatasetTime(q.Get("from")); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseDatasetTime(q.Get("to")); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+	if v := q.Get("min_rating"); v != "" {
+		if filter.MinRating, err = strconv.Atoi(v); err != nil || filter.MinRating < 0 || filter.MinRating > 5 {
+			return filter, fmt.Errorf("invalid min_rating %q", v)
+		}
+	}
+	for name, dst := range map[string]*bool{
+		"validated": &filter.IncludeValidated,
+		"edited":    &filter.EditedOnly,
+		"metadata":  &filter.WithMetadata,
+	} {
+		if v := q.Get(name); v != "" {
+			if *dst, err = strconv.ParseBool(v); err != nil {
+				return filter, fmt.Errorf("invalid %s %q", name, v)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// parseDatasetTime принимает RFC 3339 или дату YYYY-MM-DD.
+func parseDatasetTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+// POST /api/v1/jobs/{id}/rating
+func (h *OrchestratorHandler) handleRateJob(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var req rateJobReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+
+	job, err := h.jobService.RateJob(r.Context(), id, req.Rating)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrInvalidRating):
+			writeError(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("rate job failed", "id", id, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// PUT /api/v1/jobs/{id}/files — ручная правка файлов, сохраняется новой ревизией
+func (h *OrchestratorHandler) handleEditFiles(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var req editFilesReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+
+	job, err := h.jobService.EditFiles(r.Context(), id, req.Files)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrJobBusy):
+			writeError(w, http.StatusConflict, err)
+		default:
+			h.logger.Error("edit files failed", "id", id, "err", err)
+			writeError(w, http.StatusBadRequest, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"orchestrator/app/usecase"
+)
+
+// GET /api/v1/examples?target=terraform
+func (h *OrchestratorHandler) handleListExamples(w http.ResponseWriter, r *http.Request) {
+	examples, err := h.exampleService.List(r.Context(), r.URL.Query().Get("target"))
+	if err != nil {
+		h.logger.Error("list examples failed", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, examples)
+}
+
+// GET /api/v1/examples/search?q=...&target=terraform&limit=3 — какие примеры получит задача с таким описанием
+func (h *OrchestratorHandler) handleSearchExamples(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("q") == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("query parameter q is required"))
+		return
+	}
+	target := q.Get("target")
+	if target == "" {
+		target = "terraform"
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+
+	matches, err := h.exampleService.Search(r.Context(), target, q.Get("q"), "", limit)
+	if err != nil {
+		h.logger.Error("search examples failed", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, matches)
+}
+
+// GET /api/v1/examples/{id}
+func (h *OrchestratorHandler) handleGetExample(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	example, err := h.exampleService.Get(r.Context(), id)
+	if err != nil {
+		h.writeExampleError(w, "get example failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, example)
+}
+
+// DELETE /api/v1/examples/{id}
+func (h *OrchestratorHandler) handleDeleteExample(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.exampleService.Delete(r.Context(), id); err != nil {
+		h.writeExampleError(w, "delete example failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /api/v1/examples/{id}/pin — закрепить, DELETE — снять закрепление
+func (h *OrchestratorHandler) handlePinExample(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	example, err := h.exampleService.Pin(r.Context(), id, r.Method == http.MethodPost)
+	if err != nil {
+		h.writeExampleError(w, "pin example failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, example)
+}
+
+// POST /api/v1/examples/{id}/exclude — исключить из подбора, DELETE — вернуть
+func (h *OrchestratorHandler) handleExcludeExample(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	example, err := h.exampleService.Exclude(r.Context(), id, r.Method == http.MethodPost)
+	if err != nil {
+		h.writeExampleError(w, "exclude example failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, example)
+}
+
+func (h *OrchestratorHandler) writeExampleError(w http.ResponseWriter, msg, id string, err error) {
+	if errors.Is(err, usecase.ErrExampleNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	h.logger.Error(msg, "id", id, "err", err)
+	writeError(w, http.StatusInternalServerError, err)
+}
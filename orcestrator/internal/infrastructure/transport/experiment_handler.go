@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"orchestrator/app/usecase"
+	"orchestrator/internal/domain/entity"
+)
+
+type experimentReq struct {
+	Name     string                     `json:"name"`
+	Target   string                     `json:"target"`
+	Variants []entity.ExperimentVariant `json:"variants"`
+}
+
+// GET /api/v1/experiments
+func (h *OrchestratorHandler) handleListExperiments(w http.ResponseWriter, r *http.Request) {
+	exps, err := h.experimentService.List(r.Context())
+	if err != nil {
+		h.logger.Error("list experiments failed", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, exps)
+}
+
+// POST /api/v1/experiments
+func (h *OrchestratorHandler) handleCreateExperiment(w http.ResponseWriter, r *http.Request) {
+	var req experimentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+	if req.Target == "" {
+		req.Target = "terraform"
+	}
+
+	exp, err := h.experimentService.Create(r.Context(), entity.Experiment{
+		Name:     req.Name,
+		Target:   req.Target,
+		Variants: req.Variants,
+	})
+	if err != nil {
+		if errors.Is(err, usecase.ErrExperimentConflict) {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		h.logger.Error("create experiment failed", "err", err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, exp)
+}
+
+// GET /api/v1/experiments/{id}
+func (h *OrchestratorHandler) handleGetExperiment(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	exp, err := h.experimentService.Get(r.Context(), id)
+	if err != nil {
+		h.writeExperimentError(w, "get experiment failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, exp)
+}
+
+// POST /api/v1/experiments/{id}/stop
+func (h *OrchestratorHandler) handleStopExperiment(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	exp, err := h.experimentService.Stop(r.Context(), id)
+	if err != nil {
+		h.writeExperimentError(w, "stop experiment failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, exp)
+}
+
+// DELETE /api/v1/experiments/{id}
+func (h *OrchestratorHandler) handleDeleteExperiment(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.experimentService.Delete(r.Context(), id); err != nil {
+		h.writeExperimentError(w, "delete experiment failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// GET /api/v1/experiments/{id}/summary
+func (h *OrchestratorHandler) handleExperimentSummary(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	summary, err := h.experimentService.Summary(r.Context(), id)
+	if err != nil {
+		h.writeExperimentError(w, "experiment summary failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h *OrchestratorHandler) writeExperimentError(w http.ResponseWriter, msg, id string, err error) {
+	if errors.Is(err, usecase.ErrExperimentNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	h.logger.Error(msg, "id", id, "err", err)
+	writeError(w, http.StatusInternalServerError, err)
+}
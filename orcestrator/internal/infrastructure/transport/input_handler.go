@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"orchestrator/app/usecase"
+)
+
+type setInputsReq struct {
+	Values map[string]string `json:"values"` // имя переменной -> значение
+}
+
+// GET /api/v1/jobs/{id}/inputs — значения, без которых деплой невозможен (без самих значений)
+func (h *OrchestratorHandler) handleGetInputs(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	job, err := h.jobService.GetJob(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, usecase.ErrJobNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"inputs":  job.RequiredInputs,
+		"missing": job.MissingInputs(),
+	})
+}
+
+// PUT /api/v1/jobs/{id}/inputs
+func (h *OrchestratorHandler) handleSetInputs(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var req setInputsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+
+	job, err := h.jobService.SetInputs(r.Context(), id, req.Values)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrJobBusy):
+			writeError(w, http.StatusConflict, err)
+		case errors.Is(err, usecase.ErrInvalidInputs):
+			writeError(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("set inputs failed", "id", id, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"inputs":  job.RequiredInputs,
+		"missing": job.MissingInputs(),
+	})
+}
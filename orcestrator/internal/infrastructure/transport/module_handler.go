@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// GET /api/v1/modules
+func (h *OrchestratorHandler) handleListModules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.moduleService.List())
+}
+
+// GET /api/v1/modules/search?q=...&limit=...
+func (h *OrchestratorHandler) handleSearchModules(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("query parameter q is required"))
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, h.moduleService.Search(query, limit))
+}
+
+// POST /api/v1/modules/reload — перечитать каталог (и подтянуть git) без перезапуска
+func (h *OrchestratorHandler) handleReloadModules(w http.ResponseWriter, r *http.Request) {
+	n, err := h.moduleService.Reload(r.Context())
+	if err != nil {
+		h.logger.Error("reload module catalog failed", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"modules": n})
+}
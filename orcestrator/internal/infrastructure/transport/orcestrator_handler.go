@@ -17,11 +17,20 @@ import (
 
 	"orchestrator/app/usecase"
 	"orchestrator/internal/domain/entity"
+	"orchestrator/internal/infrastructure/validator"
 )
 
 type OrchestratorHandler struct {
 	jobService        usecase.JobUsecase
 	configFileService usecase.ConfigFilesUseCase
+	budgetService     usecase.BudgetUsecase
+	promptService     usecase.PromptUsecase
+	experimentService usecase.ExperimentUsecase
+	moduleService     usecase.ModuleCatalogUsecase
+	exampleService    usecase.ExampleUsecase
+	datasetService    usecase.DatasetUsecase
+	staticAnalyzer    *validator.TerraformAnalyzer
+	policyEngine      *validator.PolicyEngine
 	logger            *slog.Logger
 	upgrader          websocket.Upgrader
 
@@ -34,6 +43,14 @@ type OrchestratorHandler struct {
 func NewOrchestratorHandler(
 	jobService usecase.JobUsecase,
 	configFileService usecase.ConfigFilesUseCase,
+	budgetService usecase.BudgetUsecase,
+	promptService usecase.PromptUsecase,
+	experimentService usecase.ExperimentUsecase,
+	moduleService usecase.ModuleCatalogUsecase,
+	exampleService usecase.ExampleUsecase,
+	datasetService usecase.DatasetUsecase,
+	staticAnalyzer *validator.TerraformAnalyzer,
+	policyEngine *validator.PolicyEngine,
 	logger *slog.Logger,
 ) *OrchestratorHandler {
 
@@ -67,6 +84,14 @@ func NewOrchestratorHandler(
 	return &OrchestratorHandler{
 		jobService:        jobService,
 		configFileService: configFileService,
+		budgetService:     budgetService,
+		promptService:     promptService,
+		experimentService: experimentService,
+		moduleService:     moduleService,
+		exampleService:    exampleService,
+		datasetService:    datasetService,
+		staticAnalyzer:    staticAnalyzer,
+		policyEngine:      policyEngine,
 		logger:            logger,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool { return true },
@@ -117,8 +142,48 @@ func (h *OrchestratorHandler) RegisterRoutes(r *mux.Router) {
 	api.HandleFunc("/jobs/{id}", h.withMetrics(h.handleGetJob)).Methods(http.MethodGet)
 	api.HandleFunc("/jobs/{id}", h.withMetrics(h.handleDeleteJob)).Methods(http.MethodDelete)
 	api.HandleFunc("/jobs/{id}/files", h.withMetrics(h.handleGetFiles)).Methods(http.MethodGet)
+	api.HandleFunc("/jobs/{id}/files", h.withMetrics(h.handleEditFiles)).Methods(http.MethodPut)
+	api.HandleFunc("/jobs/{id}/static-analysis", h.withMetrics(h.handleStaticReport)).Methods(http.MethodGet)
+	api.HandleFunc("/jobs/{id}/validation", h.withMetrics(h.handleValidationReport)).Methods(http.MethodGet)
+	api.HandleFunc("/jobs/{id}/autofix", h.withMetrics(h.handleAutofix)).Methods(http.MethodPost)
+	api.HandleFunc("/jobs/{id}/inputs", h.withMetrics(h.handleGetInputs)).Methods(http.MethodGet)
+	api.HandleFunc("/jobs/{id}/inputs", h.withMetrics(h.handleSetInputs)).Methods(http.MethodPut)
+	api.HandleFunc("/jobs/{id}/rating", h.withMetrics(h.handleRateJob)).Methods(http.MethodPost)
 	api.HandleFunc("/health", h.withMetrics(h.handleHealth)).Methods(http.MethodGet)
 	api.HandleFunc("/jobs/{id}/deploy", h.withMetrics(h.handleDeploy)).Methods(http.MethodPost)
+	api.HandleFunc("/jobs/{id}/refine", h.withMetrics(h.handleRefineJob)).Methods(http.MethodPost)
+	api.HandleFunc("/jobs/{id}/answers", h.withMetrics(h.handleAnswerQuestions)).Methods(http.MethodPost)
+	api.HandleFunc("/jobs/{id}/spec", h.withMetrics(h.handleGetSpec)).Methods(http.MethodGet)
+	api.HandleFunc("/jobs/{id}/spec", h.withMetrics(h.handleUpdateSpec)).Methods(http.MethodPut)
+	api.HandleFunc("/jobs/{id}/revisions/{revision}/activate", h.withMetrics(h.handleActivateRevision)).Methods(http.MethodPost)
+	api.HandleFunc("/budgets", h.withMetrics(h.handleListBudgets)).Methods(http.MethodGet)
+	api.HandleFunc("/budgets/{owner}", h.withMetrics(h.handleGetBudget)).Methods(http.MethodGet)
+	api.HandleFunc("/prompts", h.withMetrics(h.handleListPrompts)).Methods(http.MethodGet)
+	api.HandleFunc("/prompts", h.withMetrics(h.handleCreatePrompt)).Methods(http.MethodPost)
+	api.HandleFunc("/prompts/defaults", h.withMetrics(h.handlePromptDefaults)).Methods(http.MethodGet)
+	api.HandleFunc("/prompts/{id}", h.withMetrics(h.handleListPromptVersions)).Methods(http.MethodGet)
+	api.HandleFunc("/prompts/{id}", h.withMetrics(h.handleUpdatePrompt)).Methods(http.MethodPut)
+	api.HandleFunc("/prompts/{id}/versions/{version}", h.withMetrics(h.handleGetPrompt)).Methods(http.MethodGet)
+	api.HandleFunc("/prompts/{id}/versions/{version}", h.withMetrics(h.handleDeletePrompt)).Methods(http.MethodDelete)
+	api.HandleFunc("/experiments", h.withMetrics(h.handleListExperiments)).Methods(http.MethodGet)
+	api.HandleFunc("/experiments", h.withMetrics(h.handleCreateExperiment)).Methods(http.MethodPost)
+	api.HandleFunc("/experiments/{id}", h.withMetrics(h.handleGetExperiment)).Methods(http.MethodGet)
+	api.HandleFunc("/experiments/{id}", h.withMetrics(h.handleDeleteExperiment)).Methods(http.MethodDelete)
+	api.HandleFunc("/experiments/{id}/stop", h.withMetrics(h.handleStopExperiment)).Methods(http.MethodPost)
+	api.HandleFunc("/experiments/{id}/summary", h.withMetrics(h.handleExperimentSummary)).Methods(http.MethodGet)
+	api.HandleFunc("/modules", h.withMetrics(h.handleListModules)).Methods(http.MethodGet)
+	api.HandleFunc("/modules/search", h.withMetrics(h.handleSearchModules)).Methods(http.MethodGet)
+	api.HandleFunc("/modules/reload", h.withMetrics(h.handleReloadModules)).Methods(http.MethodPost)
+	api.HandleFunc("/examples", h.withMetrics(h.handleListExamples)).Methods(http.MethodGet)
+	api.HandleFunc("/examples/search", h.withMetrics(h.handleSearchExamples)).Methods(http.MethodGet)
+	api.HandleFunc("/examples/{id}", h.withMetrics(h.handleGetExample)).Methods(http.MethodGet)
+	api.HandleFunc("/examples/{id}", h.withMetrics(h.handleDeleteExample)).Methods(http.MethodDelete)
+	api.HandleFunc("/examples/{id}/pin", h.withMetrics(h.handlePinExample)).Methods(http.MethodPost, http.MethodDelete)
+	api.HandleFunc("/examples/{id}/exclude", h.withMetrics(h.handleExcludeExample)).Methods(http.MethodPost, http.MethodDelete)
+	api.HandleFunc("/datasets/export", h.withMetrics(h.handleExportDataset)).Methods(http.MethodGet)
+	api.HandleFunc("/rules", h.withMetrics(h.handleListRules)).Methods(http.MethodGet)
+	api.HandleFunc("/policies", h.withMetrics(h.handleListPolicies)).Methods(http.MethodGet)
+	api.HandleFunc("/policies/reload", h.withMetrics(h.handleReloadPolicies)).Methods(http.MethodPost)
 
 	// Prometheus
 	r.Handle("/metrics", promhttp.Handler())
@@ -137,6 +202,19 @@ func writeError(w http.ResponseWriter, code int, err error) {
 type createJobReq struct {
 	Description string `json:"description"`
 	Target      string `json:"target"`
+	Owner       string `json:"owner"`
+	Project     string `json:"project"` // проект с собственными настройками правил анализа
+	NoCache     bool   `json:"no_cache"`
+	// сначала задать уточняющие вопросы, а генерировать после ответов
+	ClarifyFirst bool `json:"clarify_first"`
+	// сначала извлечь структурированную спецификацию, затем генерировать по ней
+	TwoPhase bool `json:"two_phase"`
+	// сколько кандидатов сгенерировать для выбора лучшего (ограничено MAX_CANDIDATES)
+	Candidates int `json:"candidates"`
+
+	// необязательный явный выбор промпта вместо закреплённого для target
+	PromptID      string `json:"prompt_id"`
+	PromptVersion int    `json:"prompt_version"`
 }
 
 // POST /api/v1/jobs
@@ -152,7 +230,24 @@ func (h *OrchestratorHandler) handleCreateJob(w http.ResponseWriter, r *http.Req
 	}
 
 	job := entity.NewJob(req.Description, req.Target)
-	if err := h.jobService.CreateJob(r.Context(), req.Description, req.Target); err != nil {
+	if req.Owner != "" {
+		job.Owner = req.Owner
+	}
+	job.Project = req.Project
+	job.NoCache = req.NoCache
+	job.ClarifyFirst = req.ClarifyFirst
+	job.TwoPhase = req.TwoPhase
+	if req.Candidates < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("candidates must not be negative"))
+		return
+	}
+	job.Candidates = req.Candidates
+	job.PromptID, job.PromptVersion = req.PromptID, req.PromptVersion
+	if err := h.jobService.CreateJob(r.Context(), job); err != nil {
+		if errors.Is(err, usecase.ErrBudgetExceeded) {
+			writeError(w, http.StatusTooManyRequests, err)
+			return
+		}
 		h.logger.Error("create job failed", "err", err)
 		writeError(w, http.StatusInternalServerError, err)
 		return
@@ -179,14 +274,21 @@ func (h *OrchestratorHandler) handleGetJob(w http.ResponseWriter, r *http.Reques
 		writeError(w, http.StatusBadRequest, errors.New("id required"))
 		return
 	}
-	files, err := h.configFileService.GetFilesByJobID(r.Context(), id)
+	job, err := h.jobService.GetJob(r.Context(), id)
 	if err != nil {
+		if errors.Is(err, usecase.ErrJobNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
 		h.logger.Error("get job failed", "id", id, "err", err)
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	if files == nil {
-		writeError(w, http.StatusNotFound, errors.New("job not found"))
+	// только текущая ревизия: кандидаты и прошлые ревизии — через /files?revision=N
+	files, err := h.configFileService.GetFilesByRevision(r.Context(), id, job.Revision)
+	if err != nil {
+		h.logger.Error("get job failed", "id", id, "err", err)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, files)
@@ -214,7 +316,30 @@ func (h *OrchestratorHandler) handleGetFiles(w http.ResponseWriter, r *http.Requ
 		writeError(w, http.StatusBadRequest, errors.New("id required"))
 		return
 	}
-	files, err := h.configFileService.GetFilesByJobID(r.Context(), id)
+	revision := -1
+	if v := r.URL.Query().Get("revision"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid revision %q", v))
+			return
+		}
+		revision = n
+	}
+	if revision < 0 {
+		// по умолчанию — текущая ревизия задачи
+		job, err := h.jobService.GetJob(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, usecase.ErrJobNotFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			h.logger.Error("get job failed", "job_id", id, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		revision = job.Revision
+	}
+	files, err := h.configFileService.GetFilesByRevision(r.Context(), id, revision)
 	if err != nil {
 		h.logger.Error("get files failed", "job_id", id, "err", err)
 		writeError(w, http.StatusInternalServerError, err)
@@ -223,6 +348,37 @@ func (h *OrchestratorHandler) handleGetFiles(w http.ResponseWriter, r *http.Requ
 	writeJSON(w, http.StatusOK, files)
 }
 
+type refineJobReq struct {
+	Instruction string `json:"instruction"`
+}
+
+// POST /api/v1/jobs/{id}/refine
+func (h *OrchestratorHandler) handleRefineJob(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var req refineJobReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+
+	job, err := h.jobService.RefineJob(r.Context(), id, req.Instruction)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrJobNotRefinable):
+			writeError(w, http.StatusConflict, err)
+		case errors.Is(err, usecase.ErrBudgetExceeded):
+			writeError(w, http.StatusTooManyRequests, err)
+		default:
+			h.logger.Error("refine job failed", "id", id, "err", err)
+			writeError(w, http.StatusBadRequest, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
 // POST /api/v1/jobs/{id}/deploy
 func (h *OrchestratorHandler) handleDeploy(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 120*time.Second)
@@ -240,6 +396,10 @@ func (h *OrchestratorHandler) handleDeploy(w http.ResponseWriter, r *http.Reques
 			http.Error(w, "request canceled", http.StatusRequestTimeout)
 			return
 		}
+		if errors.Is(err, usecase.ErrInputsRequired) {
+			http.Error(w, "cannot deploy: "+err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, "cannot deploy: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -249,6 +409,145 @@ func (h *OrchestratorHandler) handleDeploy(w http.ResponseWriter, r *http.Reques
 	_ = json.NewEncoder(w).Encode(map[string]string{"job_id": jobID, "status": "deploying"})
 }
 
+type answersReq struct {
+	Answers map[string]string `json:"answers"` // id вопроса -> ответ
+}
+
+// POST /api/v1/jobs/{id}/answers
+func (h *OrchestratorHandler) handleAnswerQuestions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var req answersReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+
+	job, err := h.jobService.AnswerQuestions(r.Context(), id, req.Answers)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrJobNotAwaitingInput):
+			writeError(w, http.StatusConflict, err)
+		case errors.Is(err, usecase.ErrInvalidAnswers):
+			writeError(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("answer questions failed", "id", id, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// GET /api/v1/jobs/{id}/spec
+func (h *OrchestratorHandler) handleGetSpec(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	job, err := h.jobService.GetJob(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, usecase.ErrJobNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("get job failed", "id", id, "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if job.Spec == nil {
+		writeError(w, http.StatusNotFound, errors.New("job has no spec"))
+		return
+	}
+	writeJSON(w, http.StatusOK, job.Spec)
+}
+
+// PUT /api/v1/jobs/{id}/spec[?regenerate=true] — правка спецификации перезапускает генерацию;
+// для развёрнутой задачи нужен regenerate=true
+func (h *OrchestratorHandler) handleUpdateSpec(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var regenerate bool
+	if v := r.URL.Query().Get("regenerate"); v != "" {
+		var err error
+		if regenerate, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid regenerate %q", v))
+			return
+		}
+	}
+	var spec entity.InfraSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+
+	job, err := h.jobService.UpdateSpec(r.Context(), id, &spec, regenerate)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrJobBusy), errors.Is(err, usecase.ErrRegenerateRequired):
+			writeError(w, http.StatusConflict, err)
+		default:
+			h.logger.Error("update spec failed", "id", id, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// POST /api/v1/jobs/{id}/revisions/{revision}/activate
+func (h *OrchestratorHandler) handleActivateRevision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil || revision < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid revision %q", vars["revision"]))
+		return
+	}
+
+	job, err := h.jobService.ActivateRevision(r.Context(), id, revision)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound), errors.Is(err, usecase.ErrRevisionNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrJobBusy):
+			writeError(w, http.StatusConflict, err)
+		default:
+			h.logger.Error("activate revision failed", "id", id, "revision", revision, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// GET /api/v1/rules?project= — правила статического анализа с серьёзностью для проекта
+func (h *OrchestratorHandler) handleListRules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.staticAnalyzer.Rules(r.URL.Query().Get("project")))
+}
+
+// GET /api/v1/budgets
+func (h *OrchestratorHandler) handleListBudgets(w http.ResponseWriter, r *http.Request) {
+	states, err := h.budgetService.States(r.Context())
+	if err != nil {
+		h.logger.Error("list budgets failed", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, states)
+}
+
+// GET /api/v1/budgets/{owner}
+func (h *OrchestratorHandler) handleGetBudget(w http.ResponseWriter, r *http.Request) {
+	owner := mux.Vars(r)["owner"]
+	state, err := h.budgetService.State(r.Context(), owner)
+	if err != nil {
+		h.logger.Error("get budget failed", "owner", owner, "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
 // GET /api/v1/health
 func (h *OrchestratorHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	status := map[string]interface{}{
@@ -0,0 +1,22 @@
+package transport
+
+import (
+	"net/http"
+)
+
+// GET /api/v1/policies — загруженные наборы политик Rego: общий и проектов
+func (h *OrchestratorHandler) handleListPolicies(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.policyEngine.Sets())
+}
+
+// POST /api/v1/policies/reload — перечитать каталог политик без перезапуска
+func (h *OrchestratorHandler) handleReloadPolicies(w http.ResponseWriter, r *http.Request) {
+	sets, err := h.policyEngine.Reload(r.Context())
+	if err != nil {
+		// прежние политики продолжают действовать
+		h.logger.Error("reload policies failed", "err", err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sets)
+}
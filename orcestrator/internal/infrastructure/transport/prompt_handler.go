@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"orchestrator/app/usecase"
+	"orchestrator/internal/domain/entity"
+)
+
+type promptReq struct {
+	ID          string `json:"id"`
+	Target      string `json:"target"`
+	Description string `json:"description"`
+	Text        string `json:"text"`
+}
+
+// GET /api/v1/prompts
+func (h *OrchestratorHandler) handleListPrompts(w http.ResponseWriter, r *http.Request) {
+	prompts, err := h.promptService.List(r.Context())
+	if err != nil {
+		h.logger.Error("list prompts failed", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, prompts)
+}
+
+// POST /api/v1/prompts
+func (h *OrchestratorHandler) handleCreatePrompt(w http.ResponseWriter, r *http.Request) {
+	var req promptReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+	h.createPromptVersion(w, r, req)
+}
+
+// PUT /api/v1/prompts/{id} — правка создаёт новую версию
+func (h *OrchestratorHandler) handleUpdatePrompt(w http.ResponseWriter, r *http.Request) {
+	var req promptReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+	req.ID = mux.Vars(r)["id"]
+	h.createPromptVersion(w, r, req)
+}
+
+func (h *OrchestratorHandler) createPromptVersion(w http.ResponseWriter, r *http.Request, req promptReq) {
+	prompt, err := h.promptService.Create(r.Context(), entity.Prompt{
+		ID:          req.ID,
+		Target:      req.Target,
+		Description: req.Description,
+		Text:        req.Text,
+	})
+	if err != nil {
+		h.logger.Error("create prompt failed", "id", req.ID, "err", err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, prompt)
+}
+
+// GET /api/v1/prompts/defaults
+func (h *OrchestratorHandler) handlePromptDefaults(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.promptService.Defaults())
+}
+
+// GET /api/v1/prompts/{id}
+func (h *OrchestratorHandler) handleListPromptVersions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	prompts, err := h.promptService.ListVersions(r.Context(), id)
+	if err != nil {
+		h.writePromptError(w, "list prompt versions failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, prompts)
+}
+
+// GET /api/v1/prompts/{id}/versions/{version}
+func (h *OrchestratorHandler) handleGetPrompt(w http.ResponseWriter, r *http.Request) {
+	id, version, err := promptVersionFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	prompt, err := h.promptService.Get(r.Context(), id, version)
+	if err != nil {
+		h.writePromptError(w, "get prompt failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, prompt)
+}
+
+// DELETE /api/v1/prompts/{id}/versions/{version}
+func (h *OrchestratorHandler) handleDeletePrompt(w http.ResponseWriter, r *http.Request) {
+	id, version, err := promptVersionFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.promptService.Delete(r.Context(), id, version); err != nil {
+		h.writePromptError(w, "delete prompt failed", id, err)
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *OrchestratorHandler) writePromptError(w http.ResponseWriter, msg, id string, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrPromptNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, usecase.ErrPromptPinned):
+		writeError(w, http.StatusConflict, err)
+	default:
+		h.logger.Error(msg, "id", id, "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func promptVersionFromRequest(r *http.Request) (string, int, error) {
+	vars := mux.Vars(r)
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version < 1 {
+		return "", 0, fmt.Errorf("invalid prompt version %q", vars["version"])
+	}
+	return vars["id"], version, nil
+}
@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"orchestrator/app/usecase"
+	"orchestrator/internal/infrastructure/validator"
+)
+
+// GET /api/v1/jobs/{id}/static-analysis?format=json|sarif|junit|markdown|text
+func (h *OrchestratorHandler) handleStaticReport(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	reporter, ok := validator.ReporterFor(format)
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown format %q, supported: %s",
+			format, strings.Join(validator.ReportFormats(), ", ")))
+		return
+	}
+
+	data, err := h.jobService.StaticReport(r.Context(), id, reporter)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound), errors.Is(err, usecase.ErrReportNotFound):
+			writeError(w, http.StatusNotFound, err)
+		default:
+			h.logger.Error("get static report failed", "id", id, "format", format, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", reporter.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s"`, id, reporter.FileName()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// GET /api/v1/jobs/{id}/validation?revision=N (по умолчанию — текущая ревизия)
+func (h *OrchestratorHandler) handleValidationReport(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	revision := 0
+	if v := r.URL.Query().Get("revision"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid revision %q", v))
+			return
+		}
+		revision = n
+	}
+
+	report, err := h.jobService.ValidationReport(r.Context(), id, revision)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound),
+			errors.Is(err, usecase.ErrRevisionNotFound),
+			errors.Is(err, usecase.ErrValidationReportNotFound):
+			writeError(w, http.StatusNotFound, err)
+		default:
+			h.logger.Error("get validation report failed", "id", id, "revision", revision, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+type autofixReq struct {
+	Rules  []string `json:"rules"`   // пусто — все правила с автоисправлением
+	DryRun bool     `json:"dry_run"` // только показать diff, ревизию не создавать
+}
+
+// POST /api/v1/jobs/{id}/autofix
+func (h *OrchestratorHandler) handleAutofix(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var req autofixReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+			return
+		}
+	}
+
+	outcome, err := h.jobService.Autofix(r.Context(), id, req.Rules, req.DryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, usecase.ErrJobBusy), errors.Is(err, usecase.ErrValidationReportNotFound):
+			writeError(w, http.StatusConflict, err)
+		case errors.Is(err, usecase.ErrInvalidAutofix):
+			writeError(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("autofix failed", "id", id, "err", err)
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, outcome)
+}
@@ -0,0 +1,330 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// ProviderVersions — ограничения версий, которыми автоисправление закрепляет провайдеры.
+// Совпадают с schemas/providers.tf; провайдеры не из списка не закрепляются.
+var ProviderVersions = map[string]string{
+	"aws":        "~> 5.0",
+	"random":     "~> 3.6",
+	"tls":        "~> 4.0",
+	"null":       "~> 3.2",
+	"local":      "~> 2.5",
+	"kubernetes": "~> 2.0",
+	"helm":       "~> 2.0",
+}
+
+// Fix — исправление одного замечания.
+type Fix struct {
+	RuleID      string `json:"rule_id"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Description string `json:"description"`
+}
+
+// AutofixResult — файлы после исправлений и их diff относительно исходных.
+type AutofixResult struct {
+	Files   []*entity.ConfigFile `json:"-"`
+	Fixes   []Fix                `json:"fixes"`
+	Skipped []Fix                `json:"skipped,omitempty"` // замечания, которые не удалось исправить
+	Diff    string               `json:"diff"`
+}
+
+// fixer исправляет замечание в верхнеуровневом блоке файла; возвращает описание исправления
+// или ошибку, если исправить нельзя.
+type fixer func(fc *fixContext, finding *entity.ValidationConfigError, syn *hclsyntax.Block, blk *hclwrite.Block) (string, error)
+
+var fixers = map[string]fixer{
+	RuleProviderVersion:   fixProviderRequirement,
+	RuleProviderFormat:    fixProviderRequirement,
+	RuleMissingLifecycle:  fixLifecycle,
+	RuleMissingTags:       fixTags,
+	RuleVariableSensitive: fixSensitive,
+}
+
+// Fixable — есть ли у правила автоисправление. TF012 исправляется форматированием всего файла.
+func Fixable(ruleID string) bool {
+	_, ok := fixers[ruleID]
+	return ok || ruleID == RuleFormat
+}
+
+// fixContext — состояние автоисправления модуля: откуда брать теги и какие ресурсы их поддерживают.
+type fixContext struct {
+	tagsRef     hcl.Traversal // local.tags, local.common_tags или var.tags
+	tagsDeclare bool          // объявить local.tags в файле первого исправления
+	schemas     *ProviderSchemas
+}
+
+// Autofix механически исправляет замечания правил с автоисправлением, переписывая HCL
+// через hclwrite. rules ограничивает исправляемые правила; пустой список — все.
+// Файлы без исправлений возвращаются без изменений.
+func (a *TerraformAnalyzer) Autofix(files []*entity.ConfigFile, findings []entity.ValidationConfigError, rules []string) (*AutofixResult, error) {
+	only := map[string]bool{}
+	for _, id := range rules {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if !Fixable(id) {
+			return nil, fmt.Errorf("rule %s has no autofix", id)
+		}
+		only[id] = true
+	}
+
+	byFile := map[string][]entity.ValidationConfigError{}
+	for _, f := range findings {
+		if Fixable(f.RuleID) && (len(only) == 0 || only[f.RuleID]) {
+			byFile[f.File] = append(byFile[f.File], f)
+		}
+	}
+
+	fc := newFixContext(files)
+	fc.schemas = a.schemas
+	result := &AutofixResult{Fixes: []Fix{}}
+	for _, file := range files {
+		todo := byFile[file.Name]
+		if len(todo) == 0 {
+			result.Files = append(result.Files, file)
+			continue
+		}
+		sort.SliceStable(todo, func(i, j int) bool { return todo[i].Line < todo[j].Line })
+
+		content, fixes, skipped := fc.fixFile(file, todo)
+		result.Fixes = append(result.Fixes, fixes...)
+		result.Skipped = append(result.Skipped, skipped...)
+		fixed := *file
+		fixed.Content = content
+		result.Files = append(result.Files, &fixed)
+		if content != file.Content {
+			result.Diff += UnifiedDiff(file.Name, file.Content, content)
+		}
+	}
+	return result, nil
+}
+
+func newFixContext(files []*entity.ConfigFile) *fixContext {
+	locals, vars := map[string]bool{}, map[string]bool{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name, ".tf") {
+			continue
+		}
+		f, diags := hclsyntax.ParseConfig([]byte(file.Content), file.Name, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		for _, block := range f.Body.(*hclsyntax.Body).Blocks {
+			switch {
+			case block.Type == "locals":
+				for name := range block.Body.Attributes {
+					locals[name] = true
+				}
+			case block.Type == "variable" && len(block.Labels) == 1:
+				vars[block.Labels[0]] = true
+			}
+		}
+	}
+
+	fc := &fixContext{}
+	switch {
+	case locals["tags"]:
+		fc.tagsRef = traversal("local", "tags")
+	case locals["common_tags"]:
+		fc.tagsRef = traversal("local", "common_tags")
+	case vars["tags"]:
+		fc.tagsRef = traversal("var", "tags")
+	default:
+		fc.tagsRef = traversal("local", "tags")
+		fc.tagsDeclare = true
+	}
+	return fc
+}
+
+func (fc *fixContext) fixFile(file *entity.ConfigFile, todo []entity.ValidationConfigError) (string, []Fix, []Fix) {
+	skipAll := func(reason string) []Fix {
+		skipped := make([]Fix, 0, len(todo))
+		for _, f := range todo {
+			skipped = append(skipped, Fix{RuleID: f.RuleID, File: f.File, Line: f.Line, Description: reason})
+		}
+		return skipped
+	}
+
+	syn, diags := hclsyntax.ParseConfig([]byte(file.Content), file.Name, hcl.InitialPos)
+	if diags.HasErrors() {
+		return file.Content, nil, skipAll("file does not parse")
+	}
+	wf, diags := hclwrite.ParseConfig([]byte(file.Content), file.Name, hcl.InitialPos)
+	if diags.HasErrors() {
+		return file.Content, nil, skipAll("file does not parse")
+	}
+	synBlocks := syn.Body.(*hclsyntax.Body).Blocks
+	wBlocks := wf.Body().Blocks()
+
+	var fixes, skipped []Fix
+	for i := range todo {
+		f := &todo[i]
+		fix := Fix{RuleID: f.RuleID, File: f.File, Line: f.Line}
+		if f.RuleID == RuleFormat {
+			// исправленный файл форматируется целиком ниже
+			fix.Description = "reformatted " + file.Name
+			fixes = append(fixes, fix)
+			continue
+		}
+		idx := blockAtLine(synBlocks, f.Line)
+		if idx < 0 || idx >= len(wBlocks) {
+			fix.Description = "no block at the reported line"
+			skipped = append(skipped, fix)
+			continue
+		}
+		desc, err := fixers[f.RuleID](fc, f, synBlocks[idx], wBlocks[idx])
+		if err != nil {
+			fix.Description = err.Error()
+			skipped = append(skipped, fix)
+			continue
+		}
+		fix.Description = desc
+		fixes = append(fixes, fix)
+	}
+
+	if fc.tagsDeclare && hasFix(fixes, RuleMissingTags) {
+		// local.tags объявляется один раз — в файле, где теги понадобились впервые
+		body := wf.Body()
+		body.AppendNewline()
+		locals := body.AppendNewBlock("locals", nil).Body()
+		locals.SetAttributeValue("tags", cty.ObjectVal(map[string]cty.Value{
+			"managed_by": cty.StringVal("orchestrator"),
+		}))
+		fc.tagsDeclare = false
+		fixes = append(fixes, Fix{RuleID: RuleMissingTags, File: file.Name, Description: "declared local.tags"})
+	}
+
+	if len(fixes) == 0 {
+		return file.Content, nil, skipped
+	}
+	formatted, err := canonicalHCL(file.Name, wf.Bytes())
+	if err != nil {
+		return file.Content, nil, skipAll(err.Error())
+	}
+	return string(formatted), fixes, skipped
+}
+
+// blockAtLine — индекс верхнеуровневого блока, содержащего строку; -1, если такого нет.
+func blockAtLine(blocks hclsyntax.Blocks, line int) int {
+	for i, b := range blocks {
+		rng := b.Range()
+		if rng.Start.Line <= line && line <= rng.End.Line {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasFix(fixes []Fix, ruleID string) bool {
+	for _, f := range fixes {
+		if f.RuleID == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+func traversal(root, attr string) hcl.Traversal {
+	return hcl.Traversal{hcl.TraverseRoot{Name: root}, hcl.TraverseAttr{Name: attr}}
+}
+
+// fixProviderRequirement закрепляет версию провайдера и переводит строковую запись
+// required_providers в объектную.
+func fixProviderRequirement(_ *fixContext, finding *entity.ValidationConfigError, syn *hclsyntax.Block, blk *hclwrite.Block) (string, error) {
+	if syn.Type != "terraform" {
+		return "", fmt.Errorf("not a terraform block")
+	}
+	wRequired := blk.Body().Blocks()
+	for i, rp := range syn.Body.Blocks {
+		if rp.Type != "required_providers" || i >= len(wRequired) {
+			continue
+		}
+		for name, attr := range rp.Body.Attributes {
+			if attr.SrcRange.Start.Line != finding.Line {
+				continue
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || val.IsNull() || !val.IsWhollyKnown() {
+				return "", fmt.Errorf("requirement of provider %s is not a literal", name)
+			}
+
+			fields := map[string]cty.Value{}
+			switch {
+			case val.Type() == cty.String:
+				// legacy-запись aws = "~> 5.0" — это ограничение версии
+				fields["source"] = cty.StringVal("hashicorp/" + name)
+				fields["version"] = val
+			case val.Type().IsObjectType():
+				for k, v := range val.AsValueMap() {
+					fields[k] = v
+				}
+			default:
+				return "", fmt.Errorf("requirement of provider %s has unexpected type", name)
+			}
+			if _, ok := fields["version"]; !ok {
+				version, known := ProviderVersions[name]
+				if !known {
+					return "", fmt.Errorf("no default version for provider %s", name)
+				}
+				fields["version"] = cty.StringVal(version)
+			}
+
+			wRequired[i].Body().SetAttributeValue(name, cty.ObjectVal(fields))
+			return fmt.Sprintf("pinned provider %s to %s", name, fields["version"].AsString()), nil
+		}
+	}
+	return "", fmt.Errorf("no provider requirement at the reported line")
+}
+
+// fixLifecycle добавляет lifecycle с явным значением по умолчанию — поведение ресурса не меняется.
+func fixLifecycle(_ *fixContext, _ *entity.ValidationConfigError, syn *hclsyntax.Block, blk *hclwrite.Block) (string, error) {
+	if syn.Type != "resource" {
+		return "", fmt.Errorf("not a resource block")
+	}
+	if blk.Body().FirstMatchingBlock("lifecycle", nil) != nil {
+		return "", fmt.Errorf("lifecycle block already present")
+	}
+	blk.Body().AppendNewline()
+	lifecycle := blk.Body().AppendNewBlock("lifecycle", nil)
+	lifecycle.Body().SetAttributeValue("prevent_destroy", cty.False)
+	return "added lifecycle block to " + blockAddr(syn), nil
+}
+
+func fixTags(fc *fixContext, _ *entity.ValidationConfigError, syn *hclsyntax.Block, blk *hclwrite.Block) (string, error) {
+	if syn.Type != "resource" || len(syn.Labels) != 2 {
+		return "", fmt.Errorf("not a resource block")
+	}
+	if blk.Body().GetAttribute("tags") != nil {
+		return "", fmt.Errorf("tags already present")
+	}
+	// теги добавляются только ресурсам, схема которых их объявляет (у ассоциаций и политик их нет)
+	resType := syn.Labels[0]
+	schema, _ := fc.schemas.lookup("resource", resType)
+	if schema == nil {
+		return "", fmt.Errorf("no provider schema for %s", resType)
+	}
+	if schema.Attributes["tags"] == nil {
+		return "", fmt.Errorf("%s does not support tags", resType)
+	}
+	blk.Body().SetAttributeTraversal("tags", fc.tagsRef)
+	return fmt.Sprintf("set tags = %s on %s", referenceAddr(fc.tagsRef), blockAddr(syn)), nil
+}
+
+func fixSensitive(_ *fixContext, _ *entity.ValidationConfigError, syn *hclsyntax.Block, blk *hclwrite.Block) (string, error) {
+	if syn.Type != "variable" {
+		return "", fmt.Errorf("not a variable block")
+	}
+	blk.Body().SetAttributeValue("sensitive", cty.True)
+	return "marked " + blockAddr(syn) + " sensitive", nil
+}
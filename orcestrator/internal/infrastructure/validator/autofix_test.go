@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"orchestrator/internal/domain/entity"
+)
+
+func TestAutofixTagsOnlyWhereSchemaAllows(t *testing.T) {
+	const module = `resource "aws_route_table" "main" {
+  vpc_id = "vpc-1"
+}
+
+resource "aws_route_table_association" "main" {
+  subnet_id      = "subnet-1"
+  route_table_id = aws_route_table.main.id
+}
+`
+	withTags := &schemaBlock{Attributes: map[string]*schemaAttribute{"vpc_id": {}, "tags": {}}}
+	withoutTags := &schemaBlock{Attributes: map[string]*schemaAttribute{"subnet_id": {}, "route_table_id": {}}}
+	schemas := &ProviderSchemas{byName: map[string]*providerSchema{
+		"aws": {Resources: map[string]*resourceSchema{
+			"aws_route_table":             {Block: withTags},
+			"aws_route_table_association": {Block: withoutTags},
+		}},
+	}}
+	findings := []entity.ValidationConfigError{
+		{File: "main.tf", Line: 1, RuleID: RuleMissingTags},
+		{File: "main.tf", Line: 5, RuleID: RuleMissingTags},
+	}
+
+	tests := []struct {
+		name    string
+		schemas *ProviderSchemas
+		tagged  []string // ресурсы, которым добавлены теги
+	}{
+		{name: "with schemas", schemas: schemas, tagged: []string{"aws_route_table.main"}},
+		{name: "without schemas", schemas: nil, tagged: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []*entity.ConfigFile{{Name: "main.tf", Type: "terraform", Content: module}}
+			res, err := NewTerraformAnalyzer(RuleProfiles{}, tt.schemas).Autofix(files, findings, []string{RuleMissingTags})
+			if err != nil {
+				t.Fatalf("Autofix: %v", err)
+			}
+
+			var tagged []string
+			for _, fix := range res.Fixes {
+				if addr, ok := strings.CutPrefix(fix.Description, "set tags = local.tags on "); ok {
+					tagged = append(tagged, addr)
+				}
+			}
+			if strings.Join(tagged, ",") != strings.Join(tt.tagged, ",") {
+				t.Errorf("tagged %v, want %v", tagged, tt.tagged)
+			}
+			if got, want := len(res.Skipped), len(findings)-len(tt.tagged); got != want {
+				t.Errorf("skipped %d findings, want %d: %v", got, want, res.Skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// FormatFiles приводит .tf и .tfvars к каноническому виду hclwrite.Format (как terraform fmt)
+// и возвращает замечание о каждом переформатированном файле. Файл, который не разбирается
+// или изменился бы не только в пробелах, остаётся как есть.
+func FormatFiles(files []*entity.ConfigFile) []*entity.ValidationConfigError {
+	var found []*entity.ValidationConfigError
+	for _, file := range files {
+		if !isHCLFile(file) {
+			continue
+		}
+		formatted, err := canonicalHCL(file.Name, []byte(file.Content))
+		if err != nil || string(formatted) == file.Content {
+			// синтаксические ошибки покажет анализатор
+			continue
+		}
+		file.Content = string(formatted)
+		found = append(found, &entity.ValidationConfigError{
+			File:    file.Name,
+			Message: fmt.Sprintf("File %s was not canonically formatted and has been reformatted", file.Name),
+			RuleID:  RuleFormat,
+		})
+	}
+	return found
+}
+
+// checkFormat отмечает файл, отличающийся от канонического вида.
+func checkFormat(content string, found *findings) {
+	formatted, err := canonicalHCL(found.file, []byte(content))
+	if err == nil && string(formatted) != content {
+		found.add(RuleFormat, nil, fmt.Sprintf("File %s is not canonically formatted (terraform fmt)", found.file))
+	}
+}
+
+func isHCLFile(file *entity.ConfigFile) bool {
+	return file.Type == "terraform" && (strings.HasSuffix(file.Name, ".tf") || strings.HasSuffix(file.Name, ".tfvars"))
+}
+
+// canonicalHCL форматирует исходник и проверяет, что результат разбирается и состоит
+// из тех же лексем: форматирование меняет только отступы и выравнивание.
+func canonicalHCL(name string, src []byte) ([]byte, error) {
+	if _, diags := hclsyntax.ParseConfig(src, name, hcl.InitialPos); diags.HasErrors() {
+		return nil, diags
+	}
+	formatted := hclwrite.Format(src)
+	if bytes.Equal(formatted, src) {
+		return src, nil
+	}
+	if _, diags := hclsyntax.ParseConfig(formatted, name, hcl.InitialPos); diags.HasErrors() {
+		return nil, fmt.Errorf("formatted %s does not parse: %w", name, diags)
+	}
+	if !sameTokens(src, formatted, name) {
+		return nil, fmt.Errorf("formatting %s would change its tokens", name)
+	}
+	return formatted, nil
+}
+
+func sameTokens(a, b []byte, name string) bool {
+	ta, _ := hclsyntax.LexConfig(a, name, hcl.InitialPos)
+	tb, _ := hclsyntax.LexConfig(b, name, hcl.InitialPos)
+	if len(ta) != len(tb) {
+		return false
+	}
+	for i := range ta {
+		if ta[i].Type != tb[i].Type {
+			return false
+		}
+		x, y := ta[i].Bytes, tb[i].Bytes
+		if ta[i].Type == hclsyntax.TokenComment {
+			// у комментариев форматирование срезает пробелы в конце строки
+			x, y = bytes.TrimRight(x, " \t\r\n"), bytes.TrimRight(y, " \t\r\n")
+		}
+		if !bytes.Equal(x, y) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// junitReporter — JUnit XML для отчётов о тестах в CI: файл — набор тестов, замечание — тест.
+// Ошибки становятся падениями, предупреждения и info выводятся в system-out и сборку не роняют.
+type junitReporter struct{}
+
+func (junitReporter) Format() string      { return "junit" }
+func (junitReporter) FileName() string    { return "analysis_results.junit.xml" }
+func (junitReporter) ContentType() string { return "application/xml" }
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func (junitReporter) Write(w io.Writer, result *AnalysisResult, _ []Rule) error {
+	suites := map[string]*junitSuite{}
+	var order []string
+	suite := func(file string) *junitSuite {
+		if s, ok := suites[file]; ok {
+			return s
+		}
+		s := &junitSuite{Name: file}
+		suites[file] = s
+		order = append(order, file)
+		return s
+	}
+	for _, file := range result.Files {
+		suite(file)
+	}
+
+	for _, f := range result.Errors {
+		s := suite(f.File)
+		c := junitCase{Name: fmt.Sprintf("%s %s", f.RuleID, location(f)), ClassName: f.File}
+		if f.Severity == entity.SeverityError || f.Severity == "" {
+			c.Failure = &junitFailure{Message: f.Message, Type: f.RuleID, Text: f.Message}
+			s.Failures++
+		} else {
+			c.SystemOut = fmt.Sprintf("%s: %s", f.Severity, f.Message)
+		}
+		s.Cases = append(s.Cases, c)
+	}
+	for _, sf := range result.Suppressed {
+		s := suite(sf.Finding.File)
+		s.Cases = append(s.Cases, junitCase{
+			Name:      fmt.Sprintf("%s %s", sf.Finding.RuleID, location(&sf.Finding)),
+			ClassName: sf.Finding.File,
+			Skipped:   &junitSkipped{Message: "suppressed: " + sf.Reason},
+		})
+		s.Skipped++
+	}
+
+	report := junitSuites{Name: "terraform-static-analysis"}
+	for _, file := range order {
+		s := suites[file]
+		if len(s.Cases) == 0 {
+			s.Cases = append(s.Cases, junitCase{Name: "no findings", ClassName: file})
+		}
+		s.Tests = len(s.Cases)
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Suites = append(report.Suites, *s)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,256 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// PolicyEngine вычисляет политики Rego встроенным OPA над PolicyDocument задачи.
+// Каталог политик:
+//
+//	<dir>/*.rego                    — для всех проектов;
+//	<dir>/projects/<project>/*.rego — дополнительно для проекта.
+//
+// Правила deny и warn любого пакета становятся замечаниями. Файлы *_test.rego пропускаются.
+type PolicyEngine struct {
+	dir string
+
+	mu      sync.RWMutex
+	bundles map[string]*policyBundle // "" — общий набор
+}
+
+type policyBundle struct {
+	set     PolicySet
+	queries []policyQuery
+}
+
+type policyQuery struct {
+	path     string // data.terraform.s3.deny
+	severity entity.Severity
+	prepared rego.PreparedEvalQuery
+}
+
+// PolicySet — загруженный набор политик проекта (пустой Project — общий набор).
+type PolicySet struct {
+	Project  string   `json:"project,omitempty"`
+	Files    []string `json:"files"`
+	Packages []string `json:"packages"`
+}
+
+// policyResult — результат deny/warn в виде объекта; вместо него можно вернуть строку.
+type policyResult struct {
+	Msg      string `json:"msg"`
+	RuleID   string `json:"rule_id"`
+	Resource string `json:"resource"` // адрес блока, к которому относится замечание
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// NewPolicyEngine: пустой dir — политики не настроены, Evaluate ничего не находит.
+func NewPolicyEngine(dir string) *PolicyEngine {
+	return &PolicyEngine{dir: dir, bundles: map[string]*policyBundle{}}
+}
+
+// Reload перечитывает и компилирует каталог политик. При ошибке остаются прежние политики.
+func (e *PolicyEngine) Reload(ctx context.Context) ([]PolicySet, error) {
+	if e.dir == "" {
+		return nil, nil
+	}
+	shared, err := regoFiles(e.dir)
+	if err != nil {
+		return nil, err
+	}
+	bundles := map[string]*policyBundle{}
+	if bundles[""], err = compilePolicies(ctx, "", shared); err != nil {
+		return nil, err
+	}
+
+	projectsDir := filepath.Join(e.dir, "projects")
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read policy projects: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		own, err := regoFiles(filepath.Join(projectsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		// политики проекта компилируются вместе с общими и могут импортировать их пакеты
+		if bundles[entry.Name()], err = compilePolicies(ctx, entry.Name(), append(own, shared...)); err != nil {
+			return nil, err
+		}
+	}
+
+	e.mu.Lock()
+	e.bundles = bundles
+	e.mu.Unlock()
+	return e.Sets(), nil
+}
+
+// Sets — загруженные наборы политик: общий и проектов.
+func (e *PolicyEngine) Sets() []PolicySet {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	sets := make([]PolicySet, 0, len(e.bundles))
+	for _, name := range sortedKeys(e.bundles) {
+		sets = append(sets, e.bundles[name].set)
+	}
+	return sets
+}
+
+// Evaluate вычисляет политики проекта (или общие, если у проекта своих нет) над файлами задачи.
+func (e *PolicyEngine) Evaluate(ctx context.Context, files []*entity.ConfigFile, project string) ([]*entity.ValidationConfigError, error) {
+	e.mu.RLock()
+	bundle, ok := e.bundles[project]
+	if !ok {
+		bundle = e.bundles[""]
+	}
+	e.mu.RUnlock()
+	if bundle == nil || len(bundle.queries) == 0 {
+		return nil, nil
+	}
+
+	doc := NewPolicyDocument(files)
+	// OPA ожидает input из JSON-типов: map, []any, string, float64, bool
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode policy input: %w", err)
+	}
+	var input any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("decode policy input: %w", err)
+	}
+
+	var found []*entity.ValidationConfigError
+	for _, q := range bundle.queries {
+		rs, err := q.prepared.Eval(ctx, rego.EvalInput(input))
+		if err != nil {
+			return nil, fmt.Errorf("evaluate %s: %w", q.path, err)
+		}
+		for _, r := range rs {
+			for _, expr := range r.Expressions {
+				values, _ := expr.Value.([]any)
+				for _, v := range values {
+					found = append(found, policyFinding(v, q, doc))
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+func policyFinding(v any, q policyQuery, doc *PolicyDocument) *entity.ValidationConfigError {
+	var res policyResult
+	switch value := v.(type) {
+	case string:
+		res.Msg = value
+	default:
+		data, _ := json.Marshal(value)
+		if err := json.Unmarshal(data, &res); err != nil || res.Msg == "" {
+			res.Msg = string(data)
+		}
+	}
+	if res.RuleID == "" {
+		res.RuleID = RulePolicy
+	}
+	if res.Resource != "" && res.File == "" {
+		res.File, res.Line, _ = doc.Locate(res.Resource)
+	}
+	return &entity.ValidationConfigError{
+		File:     res.File,
+		Line:     res.Line,
+		Message:  fmt.Sprintf("%s (%s)", res.Msg, q.path),
+		RuleID:   res.RuleID,
+		Severity: q.severity,
+	}
+}
+
+func regoFiles(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.rego"))
+	if err != nil {
+		return nil, fmt.Errorf("list policies in %s: %w", dir, err)
+	}
+	files := paths[:0]
+	for _, p := range paths {
+		if !strings.HasSuffix(p, "_test.rego") {
+			files = append(files, p)
+		}
+	}
+	return files, nil
+}
+
+// compilePolicies компилирует модули и готовит запросы deny и warn каждого пакета, где они есть.
+func compilePolicies(ctx context.Context, project string, paths []string) (*policyBundle, error) {
+	modules := make(map[string]*ast.Module, len(paths))
+	bundle := &policyBundle{set: PolicySet{Project: project, Files: []string{}, Packages: []string{}}}
+	rules := map[string]map[string]bool{} // пакет -> deny/warn
+	for _, p := range paths {
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read policy: %w", err)
+		}
+		m, err := ast.ParseModuleWithOpts(p, string(src), ast.ParserOptions{RegoVersion: ast.RegoV1})
+		if err != nil {
+			return nil, fmt.Errorf("parse policy %s: %w", p, err)
+		}
+		modules[p] = m
+		bundle.set.Files = append(bundle.set.Files, filepath.Base(p))
+
+		pkg := m.Package.Path.String()
+		for _, r := range m.Rules {
+			if name := r.Head.Ref().String(); name == "deny" || name == "warn" {
+				if rules[pkg] == nil {
+					rules[pkg] = map[string]bool{}
+				}
+				rules[pkg][name] = true
+			}
+		}
+	}
+
+	compiler := ast.NewCompiler().WithDefaultRegoVersion(ast.RegoV1)
+	if compiler.Compile(modules); compiler.Failed() {
+		return nil, fmt.Errorf("compile policies of %s: %w", projectLabel(project), compiler.Errors)
+	}
+
+	for _, pkg := range sortedKeys(rules) {
+		bundle.set.Packages = append(bundle.set.Packages, pkg)
+		for _, kind := range []string{"deny", "warn"} {
+			if !rules[pkg][kind] {
+				continue
+			}
+			path := pkg + "." + kind
+			prepared, err := rego.New(rego.Compiler(compiler), rego.Query(path)).PrepareForEval(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("prepare %s: %w", path, err)
+			}
+			severity := entity.SeverityError
+			if kind == "warn" {
+				severity = entity.SeverityWarning
+			}
+			bundle.queries = append(bundle.queries, policyQuery{path: path, severity: severity, prepared: prepared})
+		}
+	}
+	sort.Strings(bundle.set.Files)
+	return bundle, nil
+}
+
+func projectLabel(project string) string {
+	if project == "" {
+		return "shared policies"
+	}
+	return "project " + project
+}
@@ -0,0 +1,148 @@
+package validator
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// PolicyDocument — Terraform задачи в виде JSON для политик Rego (input).
+type PolicyDocument struct {
+	Resources []PolicyBlock `json:"resources"` // resource и data; mode отличает их
+	Providers []PolicyBlock `json:"providers"`
+	Variables []PolicyBlock `json:"variables"`
+	Outputs   []PolicyBlock `json:"outputs"`
+	Modules   []PolicyBlock `json:"modules"`
+	Locals    []PolicyBlock `json:"locals"`
+	Terraform []PolicyBlock `json:"terraform"`
+}
+
+// PolicyBlock — блок HCL: атрибуты, вложенные блоки и ссылки на другие объявления.
+// Константы передаются значениями, остальные выражения — исходным текстом вида "${...}".
+type PolicyBlock struct {
+	Address    string         `json:"address"`
+	Mode       string         `json:"mode,omitempty"` // managed или data
+	Type       string         `json:"type,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	File       string         `json:"file"`
+	Line       int            `json:"line"`
+	Attributes map[string]any `json:"attributes"`
+	References []string       `json:"references"`
+}
+
+// NewPolicyDocument разбирает .tf файлы задачи; файлы с синтаксическими ошибками пропускаются.
+func NewPolicyDocument(files []*entity.ConfigFile) *PolicyDocument {
+	doc := &PolicyDocument{
+		Resources: []PolicyBlock{}, Providers: []PolicyBlock{}, Variables: []PolicyBlock{},
+		Outputs: []PolicyBlock{}, Modules: []PolicyBlock{}, Locals: []PolicyBlock{}, Terraform: []PolicyBlock{},
+	}
+	for _, file := range files {
+		if file.Type != "terraform" || !strings.HasSuffix(file.Name, ".tf") {
+			continue
+		}
+		src := []byte(file.Content)
+		f, diags := hclsyntax.ParseConfig(src, file.Name, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		for _, block := range f.Body.(*hclsyntax.Body).Blocks {
+			pb := PolicyBlock{
+				File:       file.Name,
+				Line:       block.DefRange().Start.Line,
+				Attributes: policyBody(block.Body, src),
+				References: policyReferences(block.Body),
+			}
+			switch {
+			case block.Type == "resource" && len(block.Labels) == 2:
+				pb.Mode, pb.Type, pb.Name = "managed", block.Labels[0], block.Labels[1]
+				pb.Address = pb.Type + "." + pb.Name
+				doc.Resources = append(doc.Resources, pb)
+			case block.Type == "data" && len(block.Labels) == 2:
+				pb.Mode, pb.Type, pb.Name = "data", block.Labels[0], block.Labels[1]
+				pb.Address = "data." + pb.Type + "." + pb.Name
+				doc.Resources = append(doc.Resources, pb)
+			case block.Type == "provider" && len(block.Labels) == 1:
+				pb.Name, pb.Address = block.Labels[0], "provider."+block.Labels[0]
+				doc.Providers = append(doc.Providers, pb)
+			case block.Type == "variable" && len(block.Labels) == 1:
+				pb.Name, pb.Address = block.Labels[0], "var."+block.Labels[0]
+				doc.Variables = append(doc.Variables, pb)
+			case block.Type == "output" && len(block.Labels) == 1:
+				pb.Name, pb.Address = block.Labels[0], "output."+block.Labels[0]
+				doc.Outputs = append(doc.Outputs, pb)
+			case block.Type == "module" && len(block.Labels) == 1:
+				pb.Name, pb.Address = block.Labels[0], "module."+block.Labels[0]
+				doc.Modules = append(doc.Modules, pb)
+			case block.Type == "locals":
+				pb.Address = "locals"
+				doc.Locals = append(doc.Locals, pb)
+			case block.Type == "terraform":
+				pb.Address = "terraform"
+				doc.Terraform = append(doc.Terraform, pb)
+			}
+		}
+	}
+	return doc
+}
+
+// Locate — файл и строка объявления по адресу (aws_s3_bucket.logs, var.region).
+func (d *PolicyDocument) Locate(addr string) (string, int, bool) {
+	for _, group := range [][]PolicyBlock{d.Resources, d.Providers, d.Variables, d.Outputs, d.Modules} {
+		for _, b := range group {
+			if b.Address == addr {
+				return b.File, b.Line, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// policyBody — атрибуты и вложенные блоки; блоки одного типа собираются в список.
+func policyBody(body *hclsyntax.Body, src []byte) map[string]any {
+	out := make(map[string]any, len(body.Attributes)+len(body.Blocks))
+	for _, attr := range sortedAttributes(body) {
+		out[attr.Name] = policyValue(attr.Expr, src)
+	}
+	for _, block := range body.Blocks {
+		nested := policyBody(block.Body, src)
+		if len(block.Labels) > 0 {
+			nested["_labels"] = block.Labels
+		}
+		list, _ := out[block.Type].([]any)
+		out[block.Type] = append(list, nested)
+	}
+	return out
+}
+
+func policyValue(expr hclsyntax.Expression, src []byte) any {
+	val, diags := expr.Value(nil)
+	if !diags.HasErrors() && val.IsWhollyKnown() {
+		if data, err := ctyjson.Marshal(val, val.Type()); err == nil {
+			var v any
+			if json.Unmarshal(data, &v) == nil {
+				return v
+			}
+		}
+	}
+	return "${" + strings.TrimSpace(string(expr.Range().SliceBytes(src))) + "}"
+}
+
+func policyReferences(body *hclsyntax.Body) []string {
+	seen := map[string]bool{}
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if expr, ok := node.(hclsyntax.Expression); ok {
+			for _, tr := range expr.Variables() {
+				if addr := referenceAddr(tr); addr != "" {
+					seen[addr] = true
+				}
+			}
+		}
+		return nil
+	})
+	return sortedKeys(seen)
+}
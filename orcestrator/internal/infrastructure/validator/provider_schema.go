@@ -0,0 +1,160 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ProviderSchemas — схемы провайдеров в формате `terraform providers schema -json`.
+// Позволяют проверять ресурсы без сети и terraform init.
+type ProviderSchemas struct {
+	byName map[string]*providerSchema // по локальному имени провайдера: aws, random
+}
+
+type schemaFile struct {
+	ProviderSchemas map[string]*providerSchema `json:"provider_schemas"`
+}
+
+type providerSchema struct {
+	Resources   map[string]*resourceSchema `json:"resource_schemas"`
+	DataSources map[string]*resourceSchema `json:"data_source_schemas"`
+}
+
+type resourceSchema struct {
+	Block *schemaBlock `json:"block"`
+}
+
+type schemaBlock struct {
+	Attributes map[string]*schemaAttribute `json:"attributes"`
+	BlockTypes map[string]*schemaBlockType `json:"block_types"`
+}
+
+type schemaAttribute struct {
+	Type     *cty.Type `json:"type"` // nil у атрибутов с nested_type
+	Required bool      `json:"required"`
+	Optional bool      `json:"optional"`
+	Computed bool      `json:"computed"`
+}
+
+// readOnly — атрибут только вычисляется провайдером и не задаётся в конфигурации.
+func (a *schemaAttribute) readOnly() bool {
+	return a.Computed && !a.Optional && !a.Required
+}
+
+type schemaBlockType struct {
+	NestingMode string       `json:"nesting_mode"` // single, group, list, set, map
+	Block       *schemaBlock `json:"block"`
+	MinItems    int          `json:"min_items"`
+	MaxItems    int          `json:"max_items"`
+}
+
+// maxItems — сколько раз блок может встретиться; 0 — без ограничения.
+func (b *schemaBlockType) maxItems() int {
+	if b.NestingMode == "single" || b.NestingMode == "group" {
+		return 1
+	}
+	return b.MaxItems
+}
+
+// LoadProviderSchemas читает схемы из файла или из всех *.json в каталоге.
+// Пустой путь означает проверку без схем.
+func LoadProviderSchemas(path string) (*ProviderSchemas, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat provider schemas %s: %w", path, err)
+	}
+	paths := []string{path}
+	if info.IsDir() {
+		if paths, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, fmt.Errorf("list provider schemas in %s: %w", path, err)
+		}
+	}
+
+	schemas := &ProviderSchemas{byName: map[string]*providerSchema{}}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read provider schemas %s: %w", p, err)
+		}
+		var file schemaFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("parse provider schemas %s: %w", p, err)
+		}
+		for addr, provider := range file.ProviderSchemas {
+			schemas.add(providerLocalName(addr), provider)
+		}
+	}
+	return schemas, nil
+}
+
+func (s *ProviderSchemas) add(name string, provider *providerSchema) {
+	existing, ok := s.byName[name]
+	if !ok {
+		s.byName[name] = provider
+		return
+	}
+	// один провайдер из разных файлов или реестров — объединяем
+	for k, v := range provider.Resources {
+		if existing.Resources == nil {
+			existing.Resources = map[string]*resourceSchema{}
+		}
+		existing.Resources[k] = v
+	}
+	for k, v := range provider.DataSources {
+		if existing.DataSources == nil {
+			existing.DataSources = map[string]*resourceSchema{}
+		}
+		existing.DataSources[k] = v
+	}
+}
+
+// providerLocalName — registry.terraform.io/hashicorp/aws → aws.
+func providerLocalName(addr string) string {
+	return addr[strings.LastIndex(addr, "/")+1:]
+}
+
+// Providers — локальные имена провайдеров, для которых загружены схемы.
+func (s *ProviderSchemas) Providers() []string {
+	if s == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.byName))
+	for name := range s.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup ищет схему ресурса (kind = resource) или источника данных (kind = data).
+// covered = false, если схемы провайдера этого типа не загружены — тогда проверять нечем.
+func (s *ProviderSchemas) lookup(kind, typeName string) (block *schemaBlock, covered bool) {
+	if s == nil {
+		return nil, false
+	}
+	name, _, _ := strings.Cut(typeName, "_")
+	provider, ok := s.byName[name]
+	if !ok {
+		return nil, false
+	}
+
+	schemas := provider.Resources
+	if kind == "data" {
+		schemas = provider.DataSources
+	}
+	rs, ok := schemas[typeName]
+	if !ok || rs.Block == nil {
+		return nil, true
+	}
+	return rs.Block, true
+}
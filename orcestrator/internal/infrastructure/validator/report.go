@@ -0,0 +1,221 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// ReportsDir — подкаталог задачи с отчётами статического анализа.
+const ReportsDir = "static_validator"
+
+// Reporter записывает результат анализа в одном формате.
+type Reporter interface {
+	Format() string
+	FileName() string
+	ContentType() string
+	Write(w io.Writer, result *AnalysisResult, rules []Rule) error
+}
+
+// Reporters — все форматы отчётов; каждый записывается в ReportsDir после анализа.
+var Reporters = []Reporter{
+	textReporter{},
+	jsonReporter{},
+	sarifReporter{},
+	junitReporter{},
+	markdownReporter{},
+}
+
+// ReporterFor возвращает репортёр по имени формата (text, json, sarif, junit, markdown).
+func ReporterFor(format string) (Reporter, bool) {
+	for _, r := range Reporters {
+		if r.Format() == strings.ToLower(format) {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// ReportFormats — имена поддерживаемых форматов.
+func ReportFormats() []string {
+	formats := make([]string, 0, len(Reporters))
+	for _, r := range Reporters {
+		formats = append(formats, r.Format())
+	}
+	return formats
+}
+
+// SaveReports записывает отчёты всех форматов в <dir>/static_validator. Вызывается
+// повторно, если к результату добавлены замечания вне анализатора.
+func (a *TerraformAnalyzer) SaveReports(result *AnalysisResult, dir, project string) error {
+	outputDir := filepath.Join(dir, ReportsDir)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	rules := a.Rules(project)
+	for _, r := range Reporters {
+		if err := writeReport(filepath.Join(outputDir, r.FileName()), r, result, rules); err != nil {
+			return fmt.Errorf("write %s report: %w", r.Format(), err)
+		}
+	}
+	return nil
+}
+
+func writeReport(path string, r Reporter, result *AnalysisResult, rules []Rule) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return r.Write(file, result, rules)
+}
+
+// severityCounts — число замечаний каждой серьёзности.
+func severityCounts(findings []*entity.ValidationConfigError) map[entity.Severity]int {
+	counts := map[entity.Severity]int{
+		entity.SeverityError:   0,
+		entity.SeverityWarning: 0,
+		entity.SeverityInfo:    0,
+	}
+	for _, f := range findings {
+		sev := f.Severity
+		if sev == "" {
+			sev = entity.SeverityError
+		}
+		counts[sev]++
+	}
+	return counts
+}
+
+// textReporter — построчный формат analysis_results.txt.
+type textReporter struct{}
+
+func (textReporter) Format() string      { return "text" }
+func (textReporter) FileName() string    { return "analysis_results.txt" }
+func (textReporter) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textReporter) Write(w io.Writer, result *AnalysisResult, _ []Rule) error {
+	for _, err := range result.Errors {
+		_, writeErr := fmt.Fprintf(w, "File: %s, Line: %d, Column: %d, Rule: %s, Severity: %s, Message: %s\n",
+			err.File, err.Line, err.Column, err.RuleID, err.Severity, err.Message)
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+
+	if len(result.Suppressed) > 0 {
+		if _, err := fmt.Fprintf(w, "\nSuppressed:\n"); err != nil {
+			return err
+		}
+	}
+	for _, s := range result.Suppressed {
+		_, writeErr := fmt.Fprintf(w, "File: %s, Line: %d, Column: %d, Rule: %s, Message: %s, Reason: %s (line %d)\n",
+			s.Finding.File, s.Finding.Line, s.Finding.Column, s.Finding.RuleID, s.Finding.Message, s.Reason, s.SuppressedAt)
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+	return nil
+}
+
+// jsonReporter — структурированный результат для других инструментов.
+type jsonReporter struct{}
+
+func (jsonReporter) Format() string      { return "json" }
+func (jsonReporter) FileName() string    { return "analysis_results.json" }
+func (jsonReporter) ContentType() string { return "application/json" }
+
+func (jsonReporter) Write(w io.Writer, result *AnalysisResult, _ []Rule) error {
+	report := struct {
+		Passed     bool                            `json:"passed"`
+		Files      []string                        `json:"files"`
+		Summary    map[entity.Severity]int         `json:"summary"`
+		Findings   []*entity.ValidationConfigError `json:"findings"`
+		Suppressed []*entity.SuppressedFinding     `json:"suppressed"`
+		Inputs     []entity.DeployInput            `json:"inputs"`
+	}{
+		Passed:     result.Passed,
+		Files:      nonNil(result.Files),
+		Summary:    severityCounts(result.Errors),
+		Findings:   nonNil(result.Errors),
+		Suppressed: nonNil(result.Suppressed),
+		Inputs:     nonNil(result.Inputs),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// markdownReporter — краткая сводка для PR и чатов.
+type markdownReporter struct{}
+
+func (markdownReporter) Format() string      { return "markdown" }
+func (markdownReporter) FileName() string    { return "analysis_results.md" }
+func (markdownReporter) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (markdownReporter) Write(w io.Writer, result *AnalysisResult, _ []Rule) error {
+	var b strings.Builder
+	status := "✅ passed"
+	if !result.Passed {
+		status = "❌ failed"
+	}
+	counts := severityCounts(result.Errors)
+	fmt.Fprintf(&b, "## Static analysis: %s\n\n", status)
+	fmt.Fprintf(&b, "%d file(s) analysed: %d error(s), %d warning(s), %d info, %d suppressed.\n",
+		len(result.Files), counts[entity.SeverityError], counts[entity.SeverityWarning], counts[entity.SeverityInfo], len(result.Suppressed))
+
+	if len(result.Errors) > 0 {
+		b.WriteString("\n| Severity | Rule | Location | Message |\n|---|---|---|---|\n")
+		for _, f := range result.Errors {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", f.Severity, f.RuleID, location(f), markdownCell(f.Message))
+		}
+	}
+	if len(result.Suppressed) > 0 {
+		b.WriteString("\n### Suppressed\n\n| Rule | Location | Reason |\n|---|---|---|\n")
+		for _, s := range result.Suppressed {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", s.Finding.RuleID, location(&s.Finding), markdownCell(s.Reason))
+		}
+	}
+	if len(result.Inputs) > 0 {
+		b.WriteString("\n### Required inputs\n\n")
+		for _, in := range result.Inputs {
+			note := ""
+			if in.Unsatisfiable {
+				note = " — placeholder outside a variable, fix the file"
+			}
+			fmt.Fprintf(&b, "- `%s` (%s:%d)%s\n", in.Name, in.File, in.Line, note)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func location(f *entity.ValidationConfigError) string {
+	if f.Line == 0 {
+		return f.File
+	}
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// nonNil — пустой срез вместо nil, чтобы в JSON были [] а не null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
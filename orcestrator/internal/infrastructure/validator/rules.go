@@ -0,0 +1,320 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// Идентификаторы правил. Новые правила получают следующий свободный номер своей группы.
+const (
+	RuleSyntax           = "TF001"
+	RuleStructure        = "TF002"
+	RuleProviderVersion  = "TF003"
+	RuleProviderFormat   = "TF004"
+	RuleMissingLifecycle = "TF005"
+	RuleMissingTags      = "TF006"
+	RuleHardcodedSecret  = "TF007"
+
+	RuleSchemaUnsupported = "TF008"
+	RuleSchemaRequired    = "TF009"
+	RuleSchemaType        = "TF010"
+	RuleSchemaUnknownType = "TF011"
+	RuleFormat            = "TF012"
+
+	RuleVariableType               = "VAR001"
+	RuleVariableDescription        = "VAR002"
+	RuleVariableDefaultType        = "VAR003"
+	RuleVariableSensitive          = "VAR004"
+	RuleVariableValidation         = "VAR005"
+	RulePlaceholderOutsideVariable = "VAR006"
+
+	RuleUndeclaredReference  = "REF001"
+	RuleUnusedDeclaration    = "REF002"
+	RuleDuplicateDeclaration = "REF003"
+	RuleDependencyCycle      = "REF004"
+
+	RuleUnusedSuppression = "SUP001"
+	RuleSuppressionReason = "SUP002"
+
+	RulePolicy = "POL001"
+
+	RuleLLMOutput       = "LLM001"
+	RuleSpecConformance = "SPEC001"
+)
+
+// SeverityOff в профиле проекта выключает правило.
+const SeverityOff entity.Severity = "off"
+
+// Rule — описание правила анализатора и его серьёзность по умолчанию.
+type Rule struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Severity    entity.Severity `json:"severity"`
+	Description string          `json:"description"`
+	Remediation string          `json:"remediation"`
+	Autofix     bool            `json:"autofix"` // замечания можно исправить POST /jobs/{id}/autofix
+}
+
+// Rules — реестр всех правил, включая проверки разбора ответа модели и соответствия спецификации.
+var Rules = []Rule{
+	{
+		ID: RuleSyntax, Name: "hcl-syntax", Severity: entity.SeverityError,
+		Description: "The file is not valid HCL.",
+		Remediation: "Fix the syntax error at the reported position; the file cannot be planned until it parses.",
+	},
+	{
+		ID: RuleStructure, Name: "hcl-structure", Severity: entity.SeverityError,
+		Description: "A block or attribute does not have the structure Terraform expects.",
+		Remediation: "Check block labels, nesting and attribute expressions against the Terraform language documentation.",
+	},
+	{
+		ID: RuleProviderVersion, Name: "provider-version", Severity: entity.SeverityWarning,
+		Description: "A required provider has no version constraint, so any future major version may be installed.",
+		Remediation: `Add version = "~> X.Y" to the provider entry in required_providers.`,
+	},
+	{
+		ID: RuleProviderFormat, Name: "provider-requirement-format", Severity: entity.SeverityWarning,
+		Description: "A required_providers entry uses the legacy string form instead of an object.",
+		Remediation: `Use the object form: name = { source = "hashicorp/name", version = "~> X.Y" }.`,
+	},
+	{
+		ID: RuleMissingLifecycle, Name: "resource-lifecycle", Severity: entity.SeverityWarning,
+		Description: "A resource has no lifecycle block.",
+		Remediation: "Add a lifecycle block (for example prevent_destroy or create_before_destroy) where replacement behaviour matters.",
+	},
+	{
+		ID: RuleMissingTags, Name: "resource-tags", Severity: entity.SeverityWarning,
+		Description: "A resource has no tags attribute, which breaks cost allocation and ownership tracking.",
+		Remediation: "Add tags (or rely on provider default_tags) with at least owner and environment.",
+	},
+	{
+		ID: RuleHardcodedSecret, Name: "hardcoded-secret", Severity: entity.SeverityWarning,
+		Description: "An attribute with a sensitive name has a literal value.",
+		Remediation: "Pass the value through a sensitive variable or read it from a secret manager.",
+	},
+	{
+		ID: RuleSchemaUnsupported, Name: "schema-unsupported", Severity: entity.SeverityError,
+		Description: "An argument or nested block is not defined in the provider schema, or is read-only.",
+		Remediation: "Check the argument name against the provider documentation; remove computed-only arguments.",
+	},
+	{
+		ID: RuleSchemaRequired, Name: "schema-required", Severity: entity.SeverityError,
+		Description: "A required argument or nested block is missing, or a nested block appears more times than allowed.",
+		Remediation: "Add the missing argument or block, or merge repeated single blocks.",
+	},
+	{
+		ID: RuleSchemaType, Name: "schema-type", Severity: entity.SeverityError,
+		Description: "A literal argument value cannot be converted to the type required by the provider schema.",
+		Remediation: "Use a value of the expected type, e.g. a number instead of a string or a list instead of a single value.",
+	},
+	{
+		ID: RuleSchemaUnknownType, Name: "schema-unknown-type", Severity: entity.SeverityError,
+		Description: "The resource or data source type does not exist in the loaded provider schema.",
+		Remediation: "Fix the type name or update the bundled provider schema if the provider version is newer.",
+	},
+	{
+		ID: RuleFormat, Name: "hcl-format", Severity: entity.SeverityInfo,
+		Description: "The file is not in canonical HCL format, so terraform fmt -check fails.",
+		Remediation: "Run terraform fmt. Generated files are reformatted automatically before they are saved.",
+	},
+	{
+		ID: RuleVariableType, Name: "variable-type", Severity: entity.SeverityWarning,
+		Description: "A variable has no type constraint.",
+		Remediation: "Add type = string, number, bool or a collection type.",
+	},
+	{
+		ID: RuleVariableDescription, Name: "variable-description", Severity: entity.SeverityWarning,
+		Description: "A variable has no description.",
+		Remediation: "Add a description explaining what the value is used for.",
+	},
+	{
+		ID: RuleVariableDefaultType, Name: "variable-default-type", Severity: entity.SeverityError,
+		Description: "A variable default does not match its declared type, or the type constraint is invalid.",
+		Remediation: "Change the default or the type so they agree.",
+	},
+	{
+		ID: RuleVariableSensitive, Name: "variable-sensitive", Severity: entity.SeverityWarning,
+		Description: "A variable that looks like a secret (password, token, key) is not marked sensitive.",
+		Remediation: "Add sensitive = true so the value is hidden in plans and logs.",
+	},
+	{
+		ID: RuleVariableValidation, Name: "variable-validation", Severity: entity.SeverityError,
+		Description: "A validation block lacks condition or error_message, or its condition does not check the variable.",
+		Remediation: "Give every validation block a condition on var.<name> and an error_message.",
+	},
+	{
+		ID: RulePlaceholderOutsideVariable, Name: "placeholder-outside-variable", Severity: entity.SeverityError,
+		Description: "A REPLACE_ME placeholder is used directly in a resource, local or provider, so it cannot be supplied at deploy time.",
+		Remediation: "Declare a variable (sensitive if it is a secret), reference it, and keep the placeholder only as its default.",
+	},
+	{
+		ID: RuleUndeclaredReference, Name: "undeclared-reference", Severity: entity.SeverityError,
+		Description: "A reference points to a variable, local, resource, data source or module that is not declared in any file of the job.",
+		Remediation: "Declare the missing symbol or fix the reference name.",
+	},
+	{
+		ID: RuleUnusedDeclaration, Name: "unused-declaration", Severity: entity.SeverityWarning,
+		Description: "A variable or local value is declared but never referenced.",
+		Remediation: "Remove the declaration or use it where it was intended.",
+	},
+	{
+		ID: RuleDuplicateDeclaration, Name: "duplicate-declaration", Severity: entity.SeverityError,
+		Description: "The same variable, local, resource, data source, module or output is declared more than once across files.",
+		Remediation: "Rename or remove one of the declarations.",
+	},
+	{
+		ID: RuleDependencyCycle, Name: "dependency-cycle", Severity: entity.SeverityError,
+		Description: "Declarations reference each other in a cycle that Terraform cannot order.",
+		Remediation: "Break the cycle, e.g. by moving a shared value into a variable or a separate resource.",
+	},
+	{
+		ID: RuleUnusedSuppression, Name: "unused-suppression", Severity: entity.SeverityWarning,
+		Description: "An orchestrator:ignore comment does not match any finding on the line or block it covers.",
+		Remediation: "Remove the stale comment or fix the rule ID so it matches the finding.",
+	},
+	{
+		ID: RuleSuppressionReason, Name: "suppression-reason", Severity: entity.SeverityWarning,
+		Description: "An orchestrator:ignore comment has no justification.",
+		Remediation: `Add reason="..." explaining why the finding does not apply.`,
+	},
+	{
+		ID: RulePolicy, Name: "policy", Severity: entity.SeverityError,
+		Description: "A Rego policy returned a deny (error) or warn (warning) result for the files.",
+		Remediation: "Change the configuration as the policy message says, or ask the policy owners to adjust the policy.",
+	},
+	{
+		ID: RuleLLMOutput, Name: "llm-output", Severity: entity.SeverityError,
+		Description: "The model answer could not be split into files cleanly (truncated or malformed output).",
+		Remediation: "Regenerate or refine the job; raise LLM_MAX_CONTINUATIONS if answers are cut off.",
+	},
+	{
+		ID: RuleSpecConformance, Name: "spec-conformance", Severity: entity.SeverityError,
+		Description: "The files contradict the job's infrastructure spec.",
+		Remediation: "Refine the job to follow the spec, or update the spec if it is wrong.",
+	},
+}
+
+func ruleByID(id string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// RuleProfiles — переопределения серьёзности правил: общие и по проектам.
+// Настройка проекта накладывается поверх общей.
+type RuleProfiles struct {
+	Default  map[string]entity.Severity
+	Projects map[string]map[string]entity.Severity
+}
+
+// NewRuleProfiles проверяет идентификаторы правил и значения серьёзности
+// (error, warning, info или off).
+func NewRuleProfiles(defaults map[string]string, projects map[string]map[string]string) (RuleProfiles, error) {
+	profiles := RuleProfiles{Projects: map[string]map[string]entity.Severity{}}
+
+	var err error
+	if profiles.Default, err = parseOverrides(defaults); err != nil {
+		return RuleProfiles{}, fmt.Errorf("default rules: %w", err)
+	}
+	for project, overrides := range projects {
+		if profiles.Projects[project], err = parseOverrides(overrides); err != nil {
+			return RuleProfiles{}, fmt.Errorf("rules of project %s: %w", project, err)
+		}
+	}
+	return profiles, nil
+}
+
+func parseOverrides(overrides map[string]string) (map[string]entity.Severity, error) {
+	parsed := make(map[string]entity.Severity, len(overrides))
+	for id, sev := range overrides {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if _, ok := ruleByID(id); !ok {
+			return nil, fmt.Errorf("unknown rule %s", id)
+		}
+		switch s := entity.Severity(strings.ToLower(strings.TrimSpace(sev))); s {
+		case entity.SeverityError, entity.SeverityWarning, entity.SeverityInfo, SeverityOff:
+			parsed[id] = s
+		default:
+			return nil, fmt.Errorf("rule %s: unknown severity %q", id, sev)
+		}
+	}
+	return parsed, nil
+}
+
+// Severity — серьёзность правила для проекта; SeverityOff — правило выключено.
+func (p RuleProfiles) Severity(project, ruleID string) entity.Severity {
+	if s, ok := p.override(project, ruleID); ok {
+		return s
+	}
+	if r, ok := ruleByID(ruleID); ok {
+		return r.Severity
+	}
+	return entity.SeverityError
+}
+
+func (p RuleProfiles) override(project, ruleID string) (entity.Severity, bool) {
+	if s, ok := p.Projects[project][ruleID]; ok {
+		return s, true
+	}
+	s, ok := p.Default[ruleID]
+	return s, ok
+}
+
+// Effective — реестр правил с серьёзностью, действующей для проекта.
+func (p RuleProfiles) Effective(project string) []Rule {
+	rules := make([]Rule, len(Rules))
+	for i, r := range Rules {
+		r.Severity = p.Severity(project, r.ID)
+		r.Autofix = Fixable(r.ID)
+		rules[i] = r
+	}
+	return rules
+}
+
+// Grade проставляет замечаниям серьёзность по профилю проекта и отбрасывает выключенные правила.
+// Без настройки в профиле сохраняется собственная серьёзность замечания (например,
+// предупреждение HCL), а если её нет — серьёзность правила по умолчанию.
+func (p RuleProfiles) Grade(project string, findings []*entity.ValidationConfigError) []*entity.ValidationConfigError {
+	graded := findings[:0:0]
+	for _, f := range findings {
+		sev, ok := p.override(project, f.RuleID)
+		if !ok {
+			sev = f.Severity
+		}
+		if sev == "" {
+			sev = p.Severity(project, f.RuleID)
+		}
+		if sev == SeverityOff {
+			continue
+		}
+		f.Severity = sev
+		graded = append(graded, f)
+	}
+	return graded
+}
+
+// HasErrors — есть ли среди замечаний хотя бы одно с серьёзностью error.
+func HasErrors(findings []*entity.ValidationConfigError) bool {
+	for _, f := range findings {
+		if f.Severity == entity.SeverityError || f.Severity == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectNames — проекты, для которых есть собственные настройки правил.
+func (p RuleProfiles) ProjectNames() []string {
+	names := make([]string, 0, len(p.Projects))
+	for name := range p.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,151 @@
+package validator
+
+import (
+	"encoding/json"
+	"io"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// sarifReporter — SARIF 2.1.0 для code scanning в GitHub и GitLab.
+type sarifReporter struct{}
+
+func (sarifReporter) Format() string      { return "sarif" }
+func (sarifReporter) FileName() string    { return "analysis_results.sarif" }
+func (sarifReporter) ContentType() string { return "application/sarif+json" }
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	Help                 sarifMessage       `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level   string `json:"level"`
+	Enabled bool   `json:"enabled"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    *int               `json:"ruleIndex,omitempty"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+func (sarifReporter) Write(w io.Writer, result *AnalysisResult, rules []Rule) error {
+	driver := sarifDriver{Name: "orchestrator-static-analyzer", Rules: []sarifRule{}}
+	index := make(map[string]int, len(rules))
+	for _, r := range rules {
+		index[r.ID] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               r.ID,
+			Name:             r.Name,
+			ShortDescription: sarifMessage{Text: r.Description},
+			Help:             sarifMessage{Text: r.Remediation},
+			DefaultConfiguration: sarifConfiguration{
+				Level:   sarifLevel(r.Severity),
+				Enabled: r.Severity != SeverityOff,
+			},
+		})
+	}
+
+	toResult := func(f *entity.ValidationConfigError) sarifResult {
+		res := sarifResult{
+			RuleID:  f.RuleID,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: f.File},
+			}}},
+		}
+		if i, ok := index[f.RuleID]; ok {
+			res.RuleIndex = &i
+		}
+		if f.Line > 0 {
+			res.Locations[0].PhysicalLocation.Region = &sarifRegion{StartLine: f.Line, StartColumn: f.Column}
+		}
+		return res
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, f := range result.Errors {
+		run.Results = append(run.Results, toResult(f))
+	}
+	for _, s := range result.Suppressed {
+		res := toResult(&s.Finding)
+		res.Suppressions = []sarifSuppression{{Kind: "inSource", Justification: s.Reason}}
+		run.Results = append(run.Results, res)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifLevel(sev entity.Severity) string {
+	switch sev {
+	case entity.SeverityWarning:
+		return "warning"
+	case entity.SeverityInfo:
+		return "note"
+	case SeverityOff:
+		return "none"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Мета-аргументы Terraform, которых нет в схемах провайдеров.
+var (
+	metaArguments = map[string]bool{"count": true, "for_each": true, "provider": true, "depends_on": true}
+	metaBlocks    = map[string]bool{"lifecycle": true, "provisioner": true, "connection": true}
+)
+
+// checkSchema сверяет блок resource или data со схемой провайдера.
+// Возвращает false, если схемы для типа нет и проверка не выполнялась.
+func (a *TerraformAnalyzer) checkSchema(kind string, block *hclsyntax.Block, found *findings) bool {
+	typeName, name := block.Labels[0], block.Labels[1]
+	schema, covered := a.schemas.lookup(kind, typeName)
+	if !covered {
+		return false
+	}
+	if schema == nil {
+		rng := block.LabelRanges[0]
+		found.add(RuleSchemaUnknownType, &rng, fmt.Sprintf("Unknown %s type %s in %s", kind, typeName, found.file))
+		return false
+	}
+
+	addr := typeName + "." + name
+	if kind == "data" {
+		addr = "data." + addr
+	}
+	checkBody(block.Body, schema, addr, block.DefRange(), true, found)
+	return true
+}
+
+// checkBody проверяет аргументы и вложенные блоки тела по схеме блока.
+// top — тело самого ресурса: в нём допустимы мета-аргументы.
+func checkBody(body *hclsyntax.Body, schema *schemaBlock, path string, defRange hcl.Range, top bool, found *findings) {
+	for _, attr := range sortedAttributes(body) {
+		if top && metaArguments[attr.Name] {
+			continue
+		}
+		sa, ok := schema.Attributes[attr.Name]
+		if !ok {
+			// блоки с режимом «атрибут как блок» допускают и запись name = [...]
+			if _, isBlock := schema.BlockTypes[attr.Name]; !isBlock {
+				found.add(RuleSchemaUnsupported, &attr.NameRange,
+					fmt.Sprintf("Unsupported argument %q in %s in %s", attr.Name, path, found.file))
+			}
+			continue
+		}
+		if sa.readOnly() {
+			found.add(RuleSchemaUnsupported, &attr.NameRange,
+				fmt.Sprintf("Argument %q in %s is read-only and set by the provider in %s", attr.Name, path, found.file))
+			continue
+		}
+		checkAttributeType(attr, sa, path, found)
+	}
+
+	for _, name := range sortedKeys(schema.Attributes) {
+		if _, set := body.Attributes[name]; schema.Attributes[name].Required && !set {
+			found.add(RuleSchemaRequired, &defRange,
+				fmt.Sprintf("Missing required argument %q in %s in %s", name, path, found.file))
+		}
+	}
+
+	counts := map[string]int{}
+	dynamic := map[string]bool{}
+	for _, child := range body.Blocks {
+		typ, childBody := child.Type, child.Body
+		if typ == "dynamic" {
+			if len(child.Labels) == 0 {
+				continue
+			}
+			typ, childBody = child.Labels[0], dynamicContent(child)
+			dynamic[typ] = true
+		}
+		if top && metaBlocks[typ] {
+			continue
+		}
+
+		bt, ok := schema.BlockTypes[typ]
+		if !ok {
+			rng := child.TypeRange
+			if _, isAttr := schema.Attributes[typ]; isAttr {
+				found.add(RuleSchemaUnsupported, &rng,
+					fmt.Sprintf("%q in %s is an argument, not a block; use %s = ... in %s", typ, path, typ, found.file))
+			} else {
+				found.add(RuleSchemaUnsupported, &rng,
+					fmt.Sprintf("Unsupported block type %q in %s in %s", typ, path, found.file))
+			}
+			continue
+		}
+		counts[typ]++
+		if childBody != nil && bt.Block != nil {
+			checkBody(childBody, bt.Block, path+"."+typ, child.DefRange(), false, found)
+		}
+	}
+
+	for _, typ := range sortedKeys(schema.BlockTypes) {
+		bt, n := schema.BlockTypes[typ], counts[typ]
+		if _, asAttr := body.Attributes[typ]; dynamic[typ] || asAttr {
+			// число блоков dynamic известно только при plan, а запись name = [...] проверяет terraform
+			continue
+		}
+		if bt.MinItems > 0 && n < bt.MinItems {
+			found.add(RuleSchemaRequired, &defRange,
+				fmt.Sprintf("At least %d %q block(s) required in %s in %s", bt.MinItems, typ, path, found.file))
+		}
+		if max := bt.maxItems(); max > 0 && n > max {
+			found.add(RuleSchemaRequired, &defRange,
+				fmt.Sprintf("Too many %q blocks in %s: at most %d allowed in %s", typ, path, max, found.file))
+		}
+	}
+}
+
+// checkAttributeType проверяет тип аргумента, если его значение известно без контекста (литерал).
+func checkAttributeType(attr *hclsyntax.Attribute, sa *schemaAttribute, path string, found *findings) {
+	if sa.Type == nil {
+		return
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return
+	}
+	if _, err := convert.Convert(val, *sa.Type); err != nil {
+		rng := attr.Expr.Range()
+		found.add(RuleSchemaType, &rng,
+			fmt.Sprintf("Invalid value for %q in %s: %s required in %s", attr.Name, path, sa.Type.FriendlyName(), found.file))
+	}
+}
+
+// dynamicContent — тело блока content внутри dynamic.
+func dynamicContent(block *hclsyntax.Block) *hclsyntax.Body {
+	for _, b := range block.Body.Blocks {
+		if b.Type == "content" {
+			return b.Body
+		}
+	}
+	return nil
+}
+
+func sortedAttributes(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+	})
+	return attrs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
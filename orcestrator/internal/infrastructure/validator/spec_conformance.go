@@ -0,0 +1,305 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// providerClouds сопоставляет провайдеры Terraform облакам из спецификации.
+var providerClouds = map[string]string{
+	"aws":     "aws",
+	"google":  "gcp",
+	"azurerm": "azure",
+}
+
+// publicFlags — атрибуты, которые выдают ресурсу публичный адрес.
+var publicFlags = map[string]string{
+	"aws_instance":    "associate_public_ip_address",
+	"aws_db_instance": "publicly_accessible",
+}
+
+// encryptionFlags — атрибуты шифрования at rest; по умолчанию у этих ресурсов шифрование выключено.
+var encryptionFlags = map[string]string{
+	"aws_db_instance": "storage_encrypted",
+	"aws_rds_cluster": "storage_encrypted",
+	"aws_ebs_volume":  "encrypted",
+}
+
+// SpecAnalyzer проверяет сгенерированные файлы на соответствие спецификации задачи:
+// облако, регион, адресация сети, публичные адреса и шифрование.
+type SpecAnalyzer struct{}
+
+func NewSpecAnalyzer() *SpecAnalyzer {
+	return &SpecAnalyzer{}
+}
+
+type specBlock struct {
+	file  string
+	block *hclsyntax.Block
+}
+
+func (a *SpecAnalyzer) Check(files []*entity.ConfigFile, spec *entity.InfraSpec) []*entity.ValidationConfigError {
+	if spec == nil {
+		return nil
+	}
+
+	var blocks []specBlock
+	parser := hclparse.NewParser()
+	for _, file := range files {
+		if file.Type != "terraform" || !strings.HasSuffix(file.Name, ".tf") {
+			continue
+		}
+		hclFile, diags := parser.ParseHCL([]byte(file.Content), file.Name)
+		if diags.HasErrors() {
+			// синтаксические ошибки уже сообщил статический анализатор
+			continue
+		}
+		body, ok := hclFile.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, b := range body.Blocks {
+			blocks = append(blocks, specBlock{file: file.Name, block: b})
+		}
+	}
+
+	variables := variableDefaults(blocks)
+	subnets := specSubnets(blocks, spec, variables)
+
+	var problems []*entity.ValidationConfigError
+	for _, sb := range blocks {
+		b := sb.block
+		switch {
+		case b.Type == "provider" && len(b.Labels) == 1:
+			problems = append(problems, checkProvider(sb, spec, variables)...)
+		case b.Type == "resource" && len(b.Labels) == 2:
+			problems = append(problems, checkResource(sb, spec, variables, subnets)...)
+		}
+	}
+	return problems
+}
+
+func checkProvider(sb specBlock, spec *entity.InfraSpec, variables map[string]cty.Value) []*entity.ValidationConfigError {
+	var problems []*entity.ValidationConfigError
+	name := sb.block.Labels[0]
+
+	if cloud, ok := providerClouds[name]; ok && spec.Cloud != "" && cloud != spec.Cloud {
+		problems = append(problems, specProblem(sb.file, sb.block.DefRange(),
+			"spec says cloud %s but provider %q targets %s", spec.Cloud, name, cloud))
+	}
+
+	if spec.Region != "" {
+		if attr, ok := sb.block.Body.Attributes["region"]; ok {
+			if region, ok := stringValue(attr.Expr, variables); ok && region != spec.Region {
+				problems = append(problems, specProblem(sb.file, attr.SrcRange,
+					"spec says region %s but provider %q uses %s", spec.Region, name, region))
+			}
+		}
+	}
+	return problems
+}
+
+func checkResource(sb specBlock, spec *entity.InfraSpec, variables map[string]cty.Value, subnets map[string]*entity.SpecSubnet) []*entity.ValidationConfigError {
+	var problems []*entity.ValidationConfigError
+	resType, resName := sb.block.Labels[0], sb.block.Labels[1]
+	attrs := sb.block.Body.Attributes
+	addr := resType + "." + resName
+
+	switch resType {
+	case "aws_vpc":
+		if attr, ok := attrs["cidr_block"]; ok && spec.Network.CIDR != "" {
+			if cidr, ok := stringValue(attr.Expr, variables); ok && cidr != spec.Network.CIDR {
+				problems = append(problems, specProblem(sb.file, attr.SrcRange,
+					"spec says network CIDR %s but %s uses %s", spec.Network.CIDR, addr, cidr))
+			}
+		}
+	case "aws_subnet":
+		problems = append(problems, checkSubnet(sb, spec, variables, subnets[resName])...)
+	}
+
+	if flag, ok := publicFlags[resType]; ok {
+		if attr, ok := attrs[flag]; ok {
+			if public, ok := boolValue(attr.Expr, variables); ok && public {
+				component := spec.Component(resName)
+				subnet := referencedSubnet(attrs["subnet_id"], subnets)
+				switch {
+				case component != nil && !component.PublicIP:
+					problems = append(problems, specProblem(sb.file, attr.SrcRange,
+						"spec says component %s has no public IP but %s sets %s = true", component.Name, addr, flag))
+				case subnet != nil && !subnet.Public:
+					problems = append(problems, specProblem(sb.file, attr.SrcRange,
+						"spec says subnet %s is private but %s in it sets %s = true", subnet.Name, addr, flag))
+				case component == nil && spec.PrivateOnly():
+					problems = append(problems, specProblem(sb.file, attr.SrcRange,
+						"spec describes a private-only network but %s sets %s = true", addr, flag))
+				}
+			}
+		}
+	}
+
+	if flag, ok := encryptionFlags[resType]; ok && spec.Security.EncryptAtRest {
+		encrypted := false
+		rng := sb.block.DefRange()
+		if attr, ok := attrs[flag]; ok {
+			rng = attr.SrcRange
+			if v, ok := boolValue(attr.Expr, variables); ok {
+				encrypted = v
+			} else {
+				// значение вычисляется во время plan — считаем, что автор знает, что делает
+				encrypted = true
+			}
+		}
+		if !encrypted {
+			problems = append(problems, specProblem(sb.file, rng,
+				"spec requires encryption at rest but %s does not set %s = true", addr, flag))
+		}
+	}
+
+	return problems
+}
+
+func checkSubnet(sb specBlock, spec *entity.InfraSpec, variables map[string]cty.Value, specSubnet *entity.SpecSubnet) []*entity.ValidationConfigError {
+	var problems []*entity.ValidationConfigError
+	attrs := sb.block.Body.Attributes
+	addr := "aws_subnet." + sb.block.Labels[1]
+
+	if attr, ok := attrs["cidr_block"]; ok && len(spec.Network.Subnets) > 0 {
+		if cidr, ok := stringValue(attr.Expr, variables); ok && subnetByCIDR(spec, cidr) == nil {
+			problems = append(problems, specProblem(sb.file, attr.SrcRange,
+				"%s uses CIDR %s which is not one of the subnets in the spec", addr, cidr))
+		}
+	}
+
+	if attr, ok := attrs["map_public_ip_on_launch"]; ok {
+		if public, ok := boolValue(attr.Expr, variables); ok && public {
+			switch {
+			case specSubnet != nil && !specSubnet.Public:
+				problems = append(problems, specProblem(sb.file, attr.SrcRange,
+					"spec says subnet %s is private but %s sets map_public_ip_on_launch = true", specSubnet.Name, addr))
+			case specSubnet == nil && spec.PrivateOnly():
+				problems = append(problems, specProblem(sb.file, attr.SrcRange,
+					"spec describes a private-only network but %s sets map_public_ip_on_launch = true", addr))
+			}
+		}
+	}
+	return problems
+}
+
+// specSubnets сопоставляет блоки aws_subnet подсетям спецификации: по CIDR, иначе по имени.
+func specSubnets(blocks []specBlock, spec *entity.InfraSpec, variables map[string]cty.Value) map[string]*entity.SpecSubnet {
+	subnets := make(map[string]*entity.SpecSubnet)
+	for _, sb := range blocks {
+		b := sb.block
+		if b.Type != "resource" || len(b.Labels) != 2 || b.Labels[0] != "aws_subnet" {
+			continue
+		}
+		var match *entity.SpecSubnet
+		if attr, ok := b.Body.Attributes["cidr_block"]; ok {
+			if cidr, ok := stringValue(attr.Expr, variables); ok {
+				match = subnetByCIDR(spec, cidr)
+			}
+		}
+		if match == nil {
+			match = spec.Subnet(b.Labels[1])
+		}
+		if match != nil {
+			subnets[b.Labels[1]] = match
+		}
+	}
+	return subnets
+}
+
+func subnetByCIDR(spec *entity.InfraSpec, cidr string) *entity.SpecSubnet {
+	for i := range spec.Network.Subnets {
+		if spec.Network.Subnets[i].CIDR == cidr {
+			return &spec.Network.Subnets[i]
+		}
+	}
+	return nil
+}
+
+// referencedSubnet — подсеть спецификации, на aws_subnet которой ссылается subnet_id.
+// Ссылки на несколько подсетей сразу не разрешаются.
+func referencedSubnet(attr *hclsyntax.Attribute, subnets map[string]*entity.SpecSubnet) *entity.SpecSubnet {
+	if attr == nil {
+		return nil
+	}
+	var found *entity.SpecSubnet
+	for _, tr := range attr.Expr.Variables() {
+		if tr.RootName() != "aws_subnet" || len(tr) < 2 {
+			continue
+		}
+		name, ok := tr[1].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		subnet := subnets[name.Name]
+		if subnet == nil || (found != nil && found != subnet) {
+			return nil
+		}
+		found = subnet
+	}
+	return found
+}
+
+// variableDefaults собирает значения по умолчанию переменных, чтобы проверять var.* ссылки.
+func variableDefaults(blocks []specBlock) map[string]cty.Value {
+	defaults := make(map[string]cty.Value)
+	for _, sb := range blocks {
+		if sb.block.Type != "variable" || len(sb.block.Labels) != 1 {
+			continue
+		}
+		attr, ok := sb.block.Body.Attributes["default"]
+		if !ok {
+			continue
+		}
+		if v, diags := attr.Expr.Value(nil); !diags.HasErrors() && v.IsWhollyKnown() {
+			defaults[sb.block.Labels[0]] = v
+		}
+	}
+	return defaults
+}
+
+func exprValue(expr hclsyntax.Expression, variables map[string]cty.Value) (cty.Value, bool) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(variables)},
+	}
+	v, diags := expr.Value(ctx)
+	if diags.HasErrors() || !v.IsWhollyKnown() || v.IsNull() {
+		return cty.NilVal, false
+	}
+	return v, true
+}
+
+func stringValue(expr hclsyntax.Expression, variables map[string]cty.Value) (string, bool) {
+	v, ok := exprValue(expr, variables)
+	if !ok || v.Type() != cty.String {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+func boolValue(expr hclsyntax.Expression, variables map[string]cty.Value) (bool, bool) {
+	v, ok := exprValue(expr, variables)
+	if !ok || v.Type() != cty.Bool {
+		return false, false
+	}
+	return v.True(), true
+}
+
+func specProblem(file string, rng hcl.Range, format string, args ...interface{}) *entity.ValidationConfigError {
+	return &entity.ValidationConfigError{
+		File:    file,
+		Message: fmt.Sprintf(format, args...),
+		Line:    rng.Start.Line,
+		Column:  rng.Start.Column,
+		RuleID:  RuleSpecConformance,
+	}
+}
@@ -2,81 +2,165 @@ package validator
 
 import (
 	"fmt"
-	"log"
 	"orchestrator/internal/domain/entity"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 type AnalysisResult struct {
 	Passed bool
+	Files  []string // проанализированные .tf файлы
 	Errors []*entity.ValidationConfigError
+	// Suppressed — замечания, подавленные комментариями orchestrator:ignore; на Passed не влияют.
+	Suppressed []*entity.SuppressedFinding
+	// Inputs — значения, которые нужно передать перед деплоем вместо плейсхолдеров.
+	Inputs []entity.DeployInput
 }
 
 var SensitiveKeywords = []string{"password", "secret", "key", "token", "access_key", "secret_key"}
 
 type Analyzer interface {
-	Analyze(files []*entity.ConfigFile, outputDir string) (*AnalysisResult, error)
+	Analyze(files []*entity.ConfigFile, outputDir, project string) (*AnalysisResult, error)
 }
 
-type TerraformAnalyzer struct{}
+type TerraformAnalyzer struct {
+	profiles RuleProfiles
+	schemas  *ProviderSchemas // nil — ресурсы не сверяются со схемами провайдеров
+}
+
+func NewTerraformAnalyzer(profiles RuleProfiles, schemas *ProviderSchemas) *TerraformAnalyzer {
+	return &TerraformAnalyzer{profiles: profiles, schemas: schemas}
+}
+
+// findings собирает замечания одного файла.
+type findings struct {
+	file  string
+	items []*entity.ValidationConfigError
+}
+
+func (f *findings) add(ruleID string, rng *hcl.Range, message string) {
+	e := &entity.ValidationConfigError{File: f.file, Message: message, RuleID: ruleID}
+	if rng != nil {
+		e.Line, e.Column = rng.Start.Line, rng.Start.Column
+	}
+	f.items = append(f.items, e)
+}
+
+// addDiags переносит диагностики HCL, сохраняя их собственную серьёзность.
+func (f *findings) addDiags(ruleID string, diags hcl.Diagnostics) {
+	for _, diag := range diags {
+		message := diag.Summary
+		if diag.Detail != "" {
+			message = fmt.Sprintf("%s: %s", diag.Summary, diag.Detail)
+		}
+		f.add(ruleID, diag.Subject, message)
+		last := f.items[len(f.items)-1]
+		last.Severity = entity.SeverityError
+		if diag.Severity == hcl.DiagWarning {
+			last.Severity = entity.SeverityWarning
+		}
+	}
+}
 
-func NewTerraformAnalyzer() *TerraformAnalyzer {
-	return &TerraformAnalyzer{}
+func (a *TerraformAnalyzer) Analyze(files []*entity.ConfigFile, outputDir, project string) (*AnalysisResult, error) {
+	return a.AnalyzeWithFindings(files, nil, outputDir, project)
 }
 
-func (a *TerraformAnalyzer) Analyze(files []*entity.ConfigFile, outputDir string) (*AnalysisResult, error) {
+// AnalyzeWithFindings — Analyze вместе с замечаниями, найденными вне анализатора (разбор ответа
+// модели, спецификация, политики, форматирование). Подавления и настройки правил проекта
+// применяются ко всем замечаниям сразу, поэтому orchestrator:ignore действует и на них.
+func (a *TerraformAnalyzer) AnalyzeWithFindings(files []*entity.ConfigFile, extra []*entity.ValidationConfigError, outputDir, project string) (*AnalysisResult, error) {
 	result := &AnalysisResult{Passed: true}
 
 	parser := hclparse.NewParser()
+	var module []*moduleFile
+	var sources []*entity.ConfigFile
+	parsedAll := true
 	for _, file := range files {
 		if file.Type != "terraform" || !strings.HasSuffix(file.Name, ".tf") {
 			continue
 		}
 
+		mf := &moduleFile{name: file.Name, found: &findings{file: file.Name}}
 		hclFile, fileDiags := parser.ParseHCL([]byte(file.Content), file.Name)
 		if fileDiags.HasErrors() {
-			for _, diag := range fileDiags {
-				result.Errors = append(result.Errors, &entity.ValidationConfigError{
-					File:    file.Name,
-					Message: fmt.Sprintf("%s: %s", diag.Summary, diag.Detail),
-					Line:    diag.Subject.Start.Line,
-					Column:  diag.Subject.Start.Column,
-				})
+			mf.found.addDiags(RuleSyntax, fileDiags)
+			parsedAll = false
+		} else {
+			mf.body, _ = hclFile.Body.(*hclsyntax.Body)
+			checkFormat(file.Content, mf.found)
+			a.analyzeFile(hclFile.Body, mf.found)
+			if mf.body != nil {
+				a.analyzeVariables(mf.body, mf.found)
 			}
-			result.Passed = false
-			continue
 		}
+		module = append(module, mf)
+		sources = append(sources, file)
+		result.Files = append(result.Files, file.Name)
+	}
+
+	// без всех файлов модуля таблица символов неполна и дала бы ложные замечания
+	if parsedAll {
+		a.analyzeSymbols(module)
+	}
+	result.Inputs = RequiredInputs(files)
 
-		fileDiags = a.analyzeFile(hclFile.Body, file.Name)
-		for _, diag := range fileDiags {
-			result.Errors = append(result.Errors, &entity.ValidationConfigError{
-				File:    file.Name,
-				Message: fmt.Sprintf("%s: %s", diag.Summary, diag.Detail),
-				Line:    diag.Subject.Start.Line,
-				Column:  diag.Subject.Start.Column,
-			})
+	byFile := make(map[string]*moduleFile, len(module))
+	for _, mf := range module {
+		byFile[mf.name] = mf
+	}
+	for _, e := range extra {
+		if mf, ok := byFile[e.File]; ok {
+			mf.found.items = append(mf.found.items, e)
+		} else {
+			// замечание не относится к .tf файлу модуля — подавить его нечем
+			result.Errors = append(result.Errors, e)
 		}
-		if fileDiags.HasErrors() {
-			result.Passed = false
+	}
+
+	for i, mf := range module {
+		var body hcl.Body
+		if mf.body != nil {
+			body = mf.body
+		}
+		kept, suppressed := applySuppressions(mf.name, parseSuppressions(sources[i].Content, body), mf.found.items)
+		result.Errors = append(result.Errors, kept...)
+		result.Suppressed = append(result.Suppressed, suppressed...)
+	}
+
+	result.Errors = a.profiles.Grade(project, result.Errors)
+	suppressed := result.Suppressed[:0]
+	for _, s := range result.Suppressed {
+		// выключенные в проекте правила не показываем и среди подавленных
+		if graded := a.profiles.Grade(project, []*entity.ValidationConfigError{&s.Finding}); len(graded) > 0 {
+			suppressed = append(suppressed, s)
 		}
 	}
+	result.Suppressed = suppressed
+	result.Passed = !HasErrors(result.Errors)
 
-	outputDir = filepath.Join(outputDir, "static_validator")
-	if err := a.saveResults(result, outputDir); err != nil {
+	if err := a.SaveReports(result, outputDir, project); err != nil {
 		return nil, fmt.Errorf("save results: %w", err)
 	}
 
 	return result, nil
 }
 
-func (a *TerraformAnalyzer) analyzeFile(body hcl.Body, fileName string) hcl.Diagnostics {
-	var diags hcl.Diagnostics
+// Grade применяет настройки правил проекта к замечаниям, найденным вне анализатора
+// (разбор ответа модели, соответствие спецификации).
+func (a *TerraformAnalyzer) Grade(project string, findings []*entity.ValidationConfigError) []*entity.ValidationConfigError {
+	return a.profiles.Grade(project, findings)
+}
+
+// Rules — правила анализатора с серьёзностью, действующей для проекта.
+func (a *TerraformAnalyzer) Rules(project string) []Rule {
+	return a.profiles.Effective(project)
+}
 
+func (a *TerraformAnalyzer) analyzeFile(body hcl.Body, found *findings) {
 	schema := &hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
 			{Type: "terraform"},
@@ -90,17 +174,33 @@ func (a *TerraformAnalyzer) analyzeFile(body hcl.Body, fileName string) hcl.Diag
 	}
 
 	content, _, contentDiags := body.PartialContent(schema)
-	diags = append(diags, contentDiags...)
-
-	diags = append(diags, a.analyzeTerraformBlocks(content, fileName)...)
-	diags = append(diags, a.analyzeResourceBlocks(content, fileName)...)
+	found.addDiags(RuleStructure, contentDiags)
 
-	return diags
+	a.analyzeTerraformBlocks(content, found)
+	a.analyzeResourceBlocks(body, content, found)
+	for _, block := range content.Blocks.OfType("data") {
+		if syntaxBlock, ok := syntaxBlockOf(body, block); ok {
+			a.checkSchema("data", syntaxBlock, found)
+		}
+	}
 }
 
-func (a *TerraformAnalyzer) analyzeTerraformBlocks(content *hcl.BodyContent, fileName string) hcl.Diagnostics {
-	var diags hcl.Diagnostics
+// syntaxBlockOf находит синтаксический блок по результату PartialContent:
+// по нему доступны все атрибуты и вложенные блоки без схемы.
+func syntaxBlockOf(body hcl.Body, block *hcl.Block) (*hclsyntax.Block, bool) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil, false
+	}
+	for _, b := range syntaxBody.Blocks {
+		if b.TypeRange == block.TypeRange {
+			return b, true
+		}
+	}
+	return nil, false
+}
 
+func (a *TerraformAnalyzer) analyzeTerraformBlocks(content *hcl.BodyContent, found *findings) {
 	for _, block := range content.Blocks.OfType("terraform") {
 		tfSchema := &hcl.BodySchema{
 			Blocks: []hcl.BlockHeaderSchema{
@@ -108,42 +208,38 @@ func (a *TerraformAnalyzer) analyzeTerraformBlocks(content *hcl.BodyContent, fil
 			},
 		}
 		tfContent, _, tfDiags := block.Body.PartialContent(tfSchema)
-		diags = append(diags, tfDiags...)
+		found.addDiags(RuleStructure, tfDiags)
 
 		for _, rpBlock := range tfContent.Blocks.OfType("required_providers") {
 			attrs, attrsDiags := rpBlock.Body.JustAttributes()
-			diags = append(diags, attrsDiags...)
+			found.addDiags(RuleStructure, attrsDiags)
 
 			for providerName, attr := range attrs {
 				val, valDiags := attr.Expr.Value(nil)
-				diags = append(diags, valDiags...)
+				found.addDiags(RuleStructure, valDiags)
 				if val.Type().IsObjectType() {
 					obj := val.AsValueMap()
 					if _, hasVersion := obj["version"]; !hasVersion {
-						diags = append(diags, &hcl.Diagnostic{
-							Severity: hcl.DiagWarning,
-							Summary:  fmt.Sprintf("Provider %s missing version constraint in %s", providerName, fileName),
-							Subject:  &attr.Range,
-						})
+						found.add(RuleProviderVersion, &attr.Range,
+							fmt.Sprintf("Provider %s missing version constraint in %s", providerName, found.file))
 					}
 				} else {
-					diags = append(diags, &hcl.Diagnostic{
-						Severity: hcl.DiagWarning,
-						Summary:  fmt.Sprintf("Provider %s has non-object requirement in %s", providerName, fileName),
-						Subject:  &attr.Range,
-					})
+					found.add(RuleProviderFormat, &attr.Range,
+						fmt.Sprintf("Provider %s has non-object requirement in %s", providerName, found.file))
 				}
 			}
 		}
 	}
-	return diags
 }
 
-func (a *TerraformAnalyzer) analyzeResourceBlocks(content *hcl.BodyContent, fileName string) hcl.Diagnostics {
-	var diags hcl.Diagnostics
-
+func (a *TerraformAnalyzer) analyzeResourceBlocks(body hcl.Body, content *hcl.BodyContent, found *findings) {
 	for _, block := range content.Blocks.OfType("resource") {
 		resType, resName := block.Labels[0], block.Labels[1]
+		syntaxBlock, ok := syntaxBlockOf(body, block)
+		if !ok {
+			continue
+		}
+		checked := a.checkSchema("resource", syntaxBlock, found)
 
 		resSchema := &hcl.BodySchema{
 			Blocks: []hcl.BlockHeaderSchema{
@@ -154,71 +250,41 @@ func (a *TerraformAnalyzer) analyzeResourceBlocks(content *hcl.BodyContent, file
 			},
 		}
 		resContent, _, resDiags := block.Body.PartialContent(resSchema)
-		diags = append(diags, resDiags...)
+		found.addDiags(RuleStructure, resDiags)
 
 		if len(resContent.Blocks.OfType("lifecycle")) == 0 {
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagWarning,
-				Summary:  fmt.Sprintf("Resource %s.%s missing lifecycle block in %s", resType, resName, fileName),
-				Subject:  &block.DefRange,
-			})
-		}
-
-		if _, hasTags := resContent.Attributes["tags"]; !hasTags {
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagWarning,
-				Summary:  fmt.Sprintf("Resource %s.%s missing tags attribute in %s", resType, resName, fileName),
-				Subject:  &block.DefRange,
-			})
-		}
-
-		allAttrs, allAttrsDiags := block.Body.JustAttributes()
-		diags = append(diags, allAttrsDiags...)
-		for attrName, attr := range allAttrs {
-			for _, kw := range SensitiveKeywords {
-				if strings.Contains(strings.ToLower(attrName), kw) {
-					_, valDiags := attr.Expr.Value(nil)
-					if !valDiags.HasErrors() {
-						diags = append(diags, &hcl.Diagnostic{
-							Severity: hcl.DiagWarning,
-							Summary:  fmt.Sprintf("Potential hardcoded sensitive value in attribute %s of resource %s.%s in %s", attrName, resType, resName, fileName),
-							Subject:  &attr.Range,
-						})
-					}
-				}
-			}
+			found.add(RuleMissingLifecycle, &block.DefRange,
+				fmt.Sprintf("Resource %s.%s missing lifecycle block in %s", resType, resName, found.file))
 		}
-	}
-	return diags
-}
 
-func (a *TerraformAnalyzer) saveResults(result *AnalysisResult, outputDir string) error {
-	if len(result.Errors) == 0 {
-		return nil
-	}
-
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("create output directory: %w", err)
-	}
+		// по схеме видно, поддерживает ли ресурс теги (у ассоциаций и политик их нет)
+		schema, _ := a.schemas.lookup("resource", resType)
+		supportsTags := !checked || schema.Attributes["tags"] != nil
+		if _, hasTags := resContent.Attributes["tags"]; !hasTags && supportsTags {
+			found.add(RuleMissingTags, &block.DefRange,
+				fmt.Sprintf("Resource %s.%s missing tags attribute in %s", resType, resName, found.file))
+		}
 
-	outputPath := filepath.Join(outputDir, "analysis_results.txt")
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("create results file: %w", err)
+		a.analyzeSecrets(syntaxBlock.Body, resType+"."+resName, found)
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			log.Printf("failed to close file: %v", err)
-		}
-	}()
+}
 
-	for _, err := range result.Errors {
-		_, writeErr := fmt.Fprintf(file, "File: %s, Line: %d, Column: %d, Message: %s\n",
-			err.File, err.Line, err.Column, err.Message)
-		if writeErr != nil {
-			return fmt.Errorf("write results: %w", writeErr)
+// analyzeSecrets ищет литеральные значения в чувствительных аргументах ресурса и его вложенных блоков.
+func (a *TerraformAnalyzer) analyzeSecrets(body *hclsyntax.Body, addr string, found *findings) {
+	for _, attr := range sortedAttributes(body) {
+		for _, kw := range SensitiveKeywords {
+			if strings.Contains(strings.ToLower(attr.Name), kw) {
+				_, valDiags := attr.Expr.Value(nil)
+				if !valDiags.HasErrors() {
+					rng := attr.SrcRange
+					found.add(RuleHardcodedSecret, &rng,
+						fmt.Sprintf("Potential hardcoded sensitive value in attribute %s of resource %s in %s", attr.Name, addr, found.file))
+				}
+				break
+			}
 		}
 	}
-
-	return nil
+	for _, child := range body.Blocks {
+		a.analyzeSecrets(child.Body, addr, found)
+	}
 }
@@ -0,0 +1,167 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// suppressionRe — комментарий вида
+//
+//	# orchestrator:ignore TF006,TF005 reason="association resources have no tags"
+//
+// Правило можно указать идентификатором или именем (resource-tags); * — любое правило.
+// Комментарий в начале файла, до первой строки кода, действует и на замечания о файле
+// целиком (без строки), например TF012.
+var suppressionRe = regexp.MustCompile(`(?:#|//)\s*orchestrator:ignore\s+([A-Za-z0-9_*,-]+)(?:\s+reason\s*=\s*"([^"]*)")?`)
+
+type suppression struct {
+	line   int // строка комментария
+	first  int // первая и последняя строки, на которые он действует
+	last   int
+	rules  []string
+	reason string
+	header bool // комментарий до первой строки кода файла
+	used   bool
+}
+
+// parseSuppressions находит комментарии подавления в файле. Комментарий в конце строки
+// действует на эту строку, на отдельной строке — на следующую строку кода; если это
+// заголовок блока, подавление действует на весь блок.
+func parseSuppressions(content string, body hcl.Body) []*suppression {
+	lines := strings.Split(content, "\n")
+	blockEnds := blockLines(body)
+
+	var suppressions []*suppression
+	code := false // встречена ли уже строка кода
+	for i, line := range lines {
+		m := suppressionRe.FindStringSubmatchIndex(line)
+		if m == nil {
+			code = code || isCodeLine(line)
+			continue
+		}
+		s := &suppression{
+			line:   i + 1,
+			rules:  strings.Split(line[m[2]:m[3]], ","),
+			header: !code && strings.TrimSpace(line[:m[0]]) == "",
+		}
+		code = code || isCodeLine(line[:m[0]])
+		if m[4] >= 0 {
+			s.reason = strings.TrimSpace(line[m[4]:m[5]])
+		}
+
+		s.first = i + 1
+		if strings.TrimSpace(line[:m[0]]) == "" {
+			// комментарий на отдельной строке — ищем следующую строку кода
+			s.first = 0
+			for j := i + 1; j < len(lines); j++ {
+				if isCodeLine(lines[j]) {
+					s.first = j + 1
+					break
+				}
+			}
+		}
+		s.last = s.first
+		if end, ok := blockEnds[s.first]; ok {
+			s.last = end
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions
+}
+
+func isCodeLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//")
+}
+
+// blockLines сопоставляет строку заголовка каждого блока (включая вложенные) с его последней строкой.
+func blockLines(body hcl.Body) map[int]int {
+	ends := map[int]int{}
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return ends
+	}
+	var walk func(b *hclsyntax.Body)
+	walk = func(b *hclsyntax.Body) {
+		for _, block := range b.Blocks {
+			start := block.TypeRange.Start.Line
+			if end := block.Range().End.Line; end > ends[start] {
+				ends[start] = end
+			}
+			walk(block.Body)
+		}
+	}
+	walk(syntaxBody)
+	return ends
+}
+
+func (s *suppression) matches(f *entity.ValidationConfigError) bool {
+	if f.Line == 0 {
+		// замечание о файле целиком подавляется только комментарием в начале файла
+		if !s.header {
+			return false
+		}
+	} else if s.first == 0 || f.Line < s.first || f.Line > s.last {
+		return false
+	}
+	rule, _ := ruleByID(f.RuleID)
+	for _, id := range s.rules {
+		id = strings.TrimSpace(id)
+		if id == "*" || strings.EqualFold(id, f.RuleID) || (rule.Name != "" && strings.EqualFold(id, rule.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySuppressions отделяет подавленные замечания файла и добавляет замечания
+// о неиспользованных подавлениях и подавлениях без обоснования.
+func applySuppressions(file string, suppressions []*suppression, findings []*entity.ValidationConfigError) (
+	kept []*entity.ValidationConfigError,
+	suppressed []*entity.SuppressedFinding,
+) {
+	for _, f := range findings {
+		var by *suppression
+		for _, s := range suppressions {
+			if s.matches(f) {
+				by = s
+				break
+			}
+		}
+		if by == nil {
+			kept = append(kept, f)
+			continue
+		}
+		by.used = true
+		suppressed = append(suppressed, &entity.SuppressedFinding{Finding: *f, Reason: by.reason, SuppressedAt: by.line})
+	}
+
+	for _, s := range suppressions {
+		rules := strings.Join(s.rules, ",")
+		if !s.used {
+			kept = append(kept, &entity.ValidationConfigError{
+				File:    file,
+				Message: fmt.Sprintf("Suppression of %s does not match any finding", rules),
+				Line:    s.line,
+				Column:  1,
+				RuleID:  RuleUnusedSuppression,
+			})
+		}
+		if s.reason == "" {
+			kept = append(kept, &entity.ValidationConfigError{
+				File:    file,
+				Message: fmt.Sprintf(`Suppression of %s has no justification; add reason="..."`, rules),
+				Line:    s.line,
+				Column:  1,
+				RuleID:  RuleSuppressionReason,
+			})
+		}
+	}
+	return kept, suppressed
+}
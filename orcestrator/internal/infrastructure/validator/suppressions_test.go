@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"testing"
+
+	"orchestrator/internal/domain/entity"
+)
+
+func TestSuppressFileLevelFinding(t *testing.T) {
+	const header = `# orchestrator:ignore TF012 reason="generated by an external tool"
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`
+	const inner = `resource "aws_s3_bucket" "logs" {
+  # orchestrator:ignore TF012 reason="generated by an external tool"
+  bucket = "logs"
+}
+`
+	tests := []struct {
+		name       string
+		content    string
+		suppressed bool
+	}{
+		{name: "comment at the top of the file", content: header, suppressed: true},
+		{name: "comment inside a block", content: inner, suppressed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := &findings{file: "main.tf"}
+			mf := parseModuleFile(t, "main.tf", tt.content, found)
+			format := &entity.ValidationConfigError{File: "main.tf", RuleID: RuleFormat, Message: "reformatted"}
+
+			kept, suppressed := applySuppressions("main.tf", parseSuppressions(tt.content, mf.body), []*entity.ValidationConfigError{format})
+			if got := len(suppressed) == 1; got != tt.suppressed {
+				t.Errorf("suppressed = %v, want %v; kept: %v", got, tt.suppressed, messages(kept))
+			}
+			if got := hasRule(kept, RuleUnusedSuppression); got == tt.suppressed {
+				t.Errorf("%s reported = %v, want %v", RuleUnusedSuppression, got, !tt.suppressed)
+			}
+		})
+	}
+}
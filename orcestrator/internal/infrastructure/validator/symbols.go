@@ -0,0 +1,340 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// symbol — объявление модуля: var.x, local.x, aws_vpc.main, data.aws_ami.x, module.x, output.x.
+type symbol struct {
+	addr  string
+	file  string
+	rng   hcl.Range
+	used  bool
+	edges map[string]bool // на какие объявления ссылается
+}
+
+// declaration — тело объявления, ссылки из которого собираются вторым проходом.
+type declaration struct {
+	from *symbol // nil — блок не объявляет символ (provider, check, import)
+	file string
+	body *hclsyntax.Body
+	expr hclsyntax.Expression // для local.x — выражение значения
+}
+
+type reference struct {
+	addr string
+	file string
+	rng  hcl.Range
+}
+
+// symbolTable — объявления и ссылки всех .tf файлов задачи.
+type symbolTable struct {
+	symbols map[string]*symbol
+	order   []*symbol
+	decls   []declaration
+	refs    []reference
+}
+
+// moduleFile — разобранный .tf файл и коллектор его замечаний.
+type moduleFile struct {
+	name  string
+	body  *hclsyntax.Body
+	found *findings
+}
+
+// analyzeSymbols проверяет ссылки между файлами модуля: необъявленные и неиспользуемые
+// символы, повторные объявления и циклы зависимостей.
+func (a *TerraformAnalyzer) analyzeSymbols(files []*moduleFile) {
+	byName := make(map[string]*findings, len(files))
+	t := &symbolTable{symbols: map[string]*symbol{}}
+	for _, f := range files {
+		byName[f.name] = f.found
+		t.declare(f, byName)
+	}
+	for _, d := range t.decls {
+		if d.expr != nil {
+			t.collectExpr(d.expr, d.from, d.file, nil)
+		} else {
+			t.collectBody(d.body, d.from, d.file, nil, true)
+		}
+	}
+
+	for _, ref := range t.refs {
+		if _, ok := t.symbols[ref.addr]; !ok {
+			rng := ref.rng
+			byName[ref.file].add(RuleUndeclaredReference, &rng,
+				fmt.Sprintf("Reference to undeclared %s %s in %s", symbolKind(ref.addr), ref.addr, ref.file))
+		}
+	}
+
+	for _, s := range t.order {
+		if !s.used && (strings.HasPrefix(s.addr, "var.") || strings.HasPrefix(s.addr, "local.")) {
+			rng := s.rng
+			byName[s.file].add(RuleUnusedDeclaration, &rng,
+				fmt.Sprintf("%s %s is declared but never used in %s", capitalize(symbolKind(s.addr)), s.addr, s.file))
+		}
+	}
+
+	for _, cycle := range t.cycles() {
+		first := t.symbols[cycle[0]]
+		rng := first.rng
+		byName[first.file].add(RuleDependencyCycle, &rng,
+			fmt.Sprintf("Dependency cycle: %s in %s", strings.Join(append(cycle, cycle[0]), " → "), first.file))
+	}
+}
+
+// declare собирает объявления файла; повторное объявление — замечание, в таблице остаётся первое.
+func (t *symbolTable) declare(f *moduleFile, byName map[string]*findings) {
+	add := func(addr string, rng hcl.Range) *symbol {
+		if prev, ok := t.symbols[addr]; ok {
+			byName[f.name].add(RuleDuplicateDeclaration, &rng,
+				fmt.Sprintf("Duplicate declaration of %s; first declared at %s:%d", addr, prev.file, prev.rng.Start.Line))
+			return nil
+		}
+		s := &symbol{addr: addr, file: f.name, rng: rng, edges: map[string]bool{}}
+		t.symbols[addr] = s
+		t.order = append(t.order, s)
+		return s
+	}
+
+	for _, block := range f.body.Blocks {
+		var s *symbol
+		switch {
+		case block.Type == "variable" && len(block.Labels) == 1:
+			s = add("var."+block.Labels[0], block.DefRange())
+		case block.Type == "resource" && len(block.Labels) == 2:
+			s = add(block.Labels[0]+"."+block.Labels[1], block.DefRange())
+		case block.Type == "data" && len(block.Labels) == 2:
+			s = add("data."+block.Labels[0]+"."+block.Labels[1], block.DefRange())
+		case block.Type == "module" && len(block.Labels) == 1:
+			s = add("module."+block.Labels[0], block.DefRange())
+		case block.Type == "output" && len(block.Labels) == 1:
+			s = add("output."+block.Labels[0], block.DefRange())
+		case block.Type == "locals":
+			for _, attr := range sortedAttributes(block.Body) {
+				if ls := add("local."+attr.Name, attr.NameRange); ls != nil {
+					t.decls = append(t.decls, declaration{from: ls, file: f.name, expr: attr.Expr})
+				}
+			}
+			continue
+		case block.Type == "terraform" || block.Type == "moved":
+			// адреса в moved и настройки terraform — не ссылки на объявления
+			continue
+		}
+		if s == nil && block.Type != "provider" && block.Type != "check" && block.Type != "import" {
+			continue
+		}
+		t.decls = append(t.decls, declaration{from: s, file: f.name, body: block.Body})
+	}
+}
+
+// collectBody собирает ссылки из тела блока. scope — имена итераторов dynamic,
+// которые ссылками на ресурсы не являются.
+func (t *symbolTable) collectBody(body *hclsyntax.Body, from *symbol, file string, scope map[string]bool, top bool) {
+	for _, attr := range sortedAttributes(body) {
+		if top && (attr.Name == "provider" || attr.Name == "providers") {
+			// ссылки на конфигурации провайдеров, а не на объявления
+			continue
+		}
+		t.collectExpr(attr.Expr, from, file, scope)
+	}
+
+	for _, block := range body.Blocks {
+		inner := scope
+		switch block.Type {
+		case "dynamic":
+			if len(block.Labels) == 0 {
+				continue
+			}
+			iterator := block.Labels[0]
+			if attr, ok := block.Body.Attributes["iterator"]; ok {
+				if tr, diags := hcl.AbsTraversalForExpr(attr.Expr); !diags.HasErrors() {
+					iterator = tr.RootName()
+				}
+			}
+			inner = map[string]bool{iterator: true}
+			for name := range scope {
+				inner[name] = true
+			}
+		case "lifecycle":
+			// ignore_changes перечисляет атрибуты самого ресурса
+			lifecycle := *block.Body
+			lifecycle.Attributes = make(hclsyntax.Attributes, len(block.Body.Attributes))
+			for name, attr := range block.Body.Attributes {
+				if name != "ignore_changes" {
+					lifecycle.Attributes[name] = attr
+				}
+			}
+			t.collectBody(&lifecycle, from, file, inner, false)
+			continue
+		}
+		t.collectBody(block.Body, from, file, inner, false)
+	}
+}
+
+func (t *symbolTable) collectExpr(expr hclsyntax.Expression, from *symbol, file string, scope map[string]bool) {
+	for _, tr := range hclsyntax.Variables(expr) {
+		addr := referenceAddr(tr)
+		if addr == "" || scope[tr.RootName()] {
+			continue
+		}
+		if from != nil && addr == from.addr && strings.HasPrefix(addr, "var.") {
+			// var.x в validation самой переменной — не зависимость и не использование
+			continue
+		}
+		t.refs = append(t.refs, reference{addr: addr, file: file, rng: tr.SourceRange()})
+		if s, ok := t.symbols[addr]; ok {
+			s.used = true
+			if from != nil {
+				from.edges[addr] = true
+			}
+		}
+	}
+}
+
+// referenceAddr — адрес объявления, на которое указывает обход; пусто для встроенных
+// имён (count, each, self, path, terraform) и неполных ссылок.
+func referenceAddr(tr hcl.Traversal) string {
+	// имена до первого индекса: aws_instance.web[0].id → aws_instance, web
+	var parts []string
+steps:
+	for _, step := range tr {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			parts = append(parts, s.Name)
+		case hcl.TraverseAttr:
+			parts = append(parts, s.Name)
+		default:
+			break steps
+		}
+	}
+
+	switch root := tr.RootName(); root {
+	case "count", "each", "self", "path", "terraform":
+		return ""
+	case "data":
+		if len(parts) < 3 {
+			return ""
+		}
+		return strings.Join(parts[:3], ".")
+	default:
+		if len(parts) < 2 {
+			return ""
+		}
+		return parts[0] + "." + parts[1]
+	}
+}
+
+// cycles находит циклы зависимостей (компоненты сильной связности алгоритмом Тарьяна)
+// и возвращает каждый как путь по объявлениям.
+func (t *symbolTable) cycles() [][]string {
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var components [][]string
+
+	var visit func(addr string)
+	visit = func(addr string) {
+		index[addr], low[addr] = len(index), len(index)
+		stack = append(stack, addr)
+		onStack[addr] = true
+		for _, next := range sortedKeys(t.symbols[addr].edges) {
+			if _, seen := index[next]; !seen {
+				visit(next)
+				low[addr] = min(low[addr], low[next])
+			} else if onStack[next] {
+				low[addr] = min(low[addr], index[next])
+			}
+		}
+		if low[addr] != index[addr] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == addr {
+				break
+			}
+		}
+		if len(component) > 1 || t.symbols[addr].edges[addr] {
+			components = append(components, component)
+		}
+	}
+	for _, s := range t.order {
+		if _, seen := index[s.addr]; !seen {
+			visit(s.addr)
+		}
+	}
+
+	cycles := make([][]string, 0, len(components))
+	for _, component := range components {
+		sort.Strings(component)
+		cycles = append(cycles, t.cyclePath(component))
+	}
+	return cycles
+}
+
+// cyclePath — путь от первого объявления компоненты обратно к нему.
+func (t *symbolTable) cyclePath(component []string) []string {
+	members := make(map[string]bool, len(component))
+	for _, addr := range component {
+		members[addr] = true
+	}
+	start := component[0]
+	path := []string{start}
+	visited := map[string]bool{start: true}
+
+	var walk func(addr string) bool
+	walk = func(addr string) bool {
+		for _, next := range sortedKeys(t.symbols[addr].edges) {
+			if next == start {
+				return true
+			}
+			if !members[next] || visited[next] {
+				continue
+			}
+			visited[next] = true
+			path = append(path, next)
+			if walk(next) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	walk(start)
+	return path
+}
+
+func symbolKind(addr string) string {
+	switch {
+	case strings.HasPrefix(addr, "var."):
+		return "input variable"
+	case strings.HasPrefix(addr, "local."):
+		return "local value"
+	case strings.HasPrefix(addr, "data."):
+		return "data source"
+	case strings.HasPrefix(addr, "module."):
+		return "module"
+	case strings.HasPrefix(addr, "output."):
+		return "output"
+	default:
+		return "resource"
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"orchestrator/internal/domain/entity"
+)
+
+func TestAnalyzeSymbolsVariableValidation(t *testing.T) {
+	const variable = `
+variable "env" {
+  type = string
+
+  validation {
+    condition     = contains(["dev", "prod"], var.env)
+    error_message = "env must be dev or prod"
+  }
+}
+`
+	tests := []struct {
+		name   string
+		module string
+		want   map[string]bool // правило -> должно ли оно сработать
+	}{
+		{
+			name: "used variable",
+			module: variable + `
+locals {
+  name = "app-${var.env}"
+}
+
+output "name" {
+  value = local.name
+}
+`,
+			want: map[string]bool{RuleDependencyCycle: false, RuleUnusedDeclaration: false},
+		},
+		{
+			name:   "referenced only by its own validation",
+			module: variable,
+			want:   map[string]bool{RuleDependencyCycle: false, RuleUnusedDeclaration: true},
+		},
+		{
+			name: "cycle between locals",
+			module: variable + `
+locals {
+  a = "${local.b}-${var.env}"
+  b = local.a
+}
+`,
+			want: map[string]bool{RuleDependencyCycle: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := &findings{file: "main.tf"}
+			mf := parseModuleFile(t, "main.tf", tt.module, found)
+			NewTerraformAnalyzer(RuleProfiles{}, nil).analyzeSymbols([]*moduleFile{mf})
+
+			for rule, want := range tt.want {
+				if got := hasRule(found.items, rule); got != want {
+					t.Errorf("%s reported = %v, want %v; findings: %v", rule, got, want, messages(found.items))
+				}
+			}
+		})
+	}
+}
+
+func parseModuleFile(t *testing.T, name, content string, found *findings) *moduleFile {
+	t.Helper()
+	f, diags := hclsyntax.ParseConfig([]byte(content), name, hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("parse %s: %v", name, diags)
+	}
+	return &moduleFile{name: name, body: f.Body.(*hclsyntax.Body), found: found}
+}
+
+func hasRule(items []*entity.ValidationConfigError, rule string) bool {
+	for _, item := range items {
+		if item.RuleID == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func messages(items []*entity.ValidationConfigError) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.RuleID+": "+item.Message)
+	}
+	return out
+}
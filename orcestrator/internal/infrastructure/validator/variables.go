@@ -0,0 +1,260 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"orchestrator/internal/domain/entity"
+)
+
+// sensitiveVariableRe — имена переменных, значения которых обычно секретны.
+// Уже, чем SensitiveKeywords: key_name или ssh_key_id секретами не являются.
+var sensitiveVariableRe = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_key|access_key|private_key|passphrase|credentials)`)
+
+// analyzeVariables проверяет контракт переменных (тип, описание, default, sensitive, validation)
+// и ищет плейсхолдеры вне переменных — их нельзя передать при деплое.
+func (a *TerraformAnalyzer) analyzeVariables(body *hclsyntax.Body, found *findings) {
+	for _, block := range body.Blocks {
+		if block.Type != "variable" || len(block.Labels) != 1 {
+			findPlaceholders(block, found)
+			continue
+		}
+		checkVariable(block, found)
+	}
+}
+
+func checkVariable(block *hclsyntax.Block, found *findings) {
+	name := block.Labels[0]
+	attrs := block.Body.Attributes
+	defRange := block.DefRange()
+
+	if _, ok := attrs["description"]; !ok {
+		found.add(RuleVariableDescription, &defRange,
+			fmt.Sprintf("Variable %q has no description in %s", name, found.file))
+	}
+
+	ty := cty.DynamicPseudoType
+	if attr, ok := attrs["type"]; ok {
+		parsed, _, diags := typeexpr.TypeConstraintWithDefaults(attr.Expr)
+		if diags.HasErrors() {
+			rng := attr.Expr.Range()
+			found.add(RuleVariableDefaultType, &rng,
+				fmt.Sprintf("Variable %q has an invalid type constraint in %s", name, found.file))
+		} else {
+			ty = parsed
+		}
+	} else {
+		found.add(RuleVariableType, &defRange,
+			fmt.Sprintf("Variable %q has no type in %s", name, found.file))
+	}
+
+	if attr, ok := attrs["default"]; ok && ty != cty.DynamicPseudoType {
+		val, diags := attr.Expr.Value(nil)
+		if !diags.HasErrors() && !val.IsNull() {
+			if _, err := convert.Convert(val, ty); err != nil {
+				rng := attr.Expr.Range()
+				found.add(RuleVariableDefaultType, &rng,
+					fmt.Sprintf("Default of variable %q does not match type %s in %s: %s",
+						name, typeexpr.TypeString(ty), found.file, err))
+			}
+		}
+	}
+
+	if sensitiveVariableRe.MatchString(name) && !isTrue(attrs["sensitive"]) {
+		found.add(RuleVariableSensitive, &defRange,
+			fmt.Sprintf("Variable %q looks sensitive but is not marked sensitive = true in %s", name, found.file))
+	}
+
+	for _, v := range block.Body.Blocks {
+		if v.Type != "validation" {
+			continue
+		}
+		vRange := v.DefRange()
+		for _, required := range []string{"condition", "error_message"} {
+			if _, ok := v.Body.Attributes[required]; !ok {
+				found.add(RuleVariableValidation, &vRange,
+					fmt.Sprintf("Validation of variable %q has no %s in %s", name, required, found.file))
+			}
+		}
+		if cond, ok := v.Body.Attributes["condition"]; ok && !referencesVariable(cond.Expr, name) {
+			rng := cond.Expr.Range()
+			found.add(RuleVariableValidation, &rng,
+				fmt.Sprintf("Validation condition of variable %q does not reference var.%s in %s", name, name, found.file))
+		}
+	}
+}
+
+// findPlaceholders отмечает плейсхолдеры в ресурсах, locals и провайдерах: значение для
+// них при деплое передать нельзя, его нужно вынести в переменную.
+func findPlaceholders(block *hclsyntax.Block, found *findings) {
+	for _, lit := range placeholderLiterals(block) {
+		rng := lit.SrcRange
+		found.add(RulePlaceholderOutsideVariable, &rng,
+			fmt.Sprintf("%s in %s cannot be supplied at deploy time; move it into a variable in %s",
+				entity.SecretPlaceholder, blockAddr(block), found.file))
+	}
+}
+
+func placeholderLiterals(block *hclsyntax.Block) []*hclsyntax.LiteralValueExpr {
+	var lits []*hclsyntax.LiteralValueExpr
+	_ = hclsyntax.VisitAll(block.Body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if lit, ok := node.(*hclsyntax.LiteralValueExpr); ok && containsPlaceholder(lit.Val) {
+			lits = append(lits, lit)
+		}
+		return nil
+	})
+	return lits
+}
+
+// RequiredInputs — переменные, вместо значений которых в файлах стоит плейсхолдер:
+// default = "REPLACE_ME" в .tf или такое же значение в .tfvars. Значение, заданное
+// в .tfvars, снимает требование. Плейсхолдеры вне переменных попадают в список
+// как Unsatisfiable: пока они есть, деплой невозможен.
+func RequiredInputs(files []*entity.ConfigFile) []entity.DeployInput {
+	parser := hclparse.NewParser()
+	var inputs []entity.DeployInput
+	index := map[string]int{}
+	assigned := map[string]bool{}
+
+	addInput := func(in entity.DeployInput) {
+		if i, ok := index[in.Name]; ok {
+			// описание и sensitive берём из объявления переменной
+			inputs[i].Description = firstNonEmpty(inputs[i].Description, in.Description)
+			inputs[i].Sensitive = inputs[i].Sensitive || in.Sensitive
+			return
+		}
+		index[in.Name] = len(inputs)
+		inputs = append(inputs, in)
+	}
+
+	for _, file := range files {
+		isVars := strings.HasSuffix(file.Name, ".tfvars")
+		if !isVars && !strings.HasSuffix(file.Name, ".tf") {
+			continue
+		}
+		hclFile, diags := parser.ParseHCL([]byte(file.Content), file.Name)
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := hclFile.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		if isVars {
+			for _, attr := range sortedAttributes(body) {
+				val, diags := attr.Expr.Value(nil)
+				if diags.HasErrors() {
+					continue
+				}
+				if !containsPlaceholder(val) {
+					assigned[attr.Name] = true
+					continue
+				}
+				addInput(entity.DeployInput{Name: attr.Name, File: file.Name, Line: attr.SrcRange.Start.Line})
+			}
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			if block.Type != "variable" || len(block.Labels) != 1 {
+				if lits := placeholderLiterals(block); len(lits) > 0 {
+					addInput(entity.DeployInput{
+						Name:          blockAddr(block),
+						File:          file.Name,
+						Line:          lits[0].SrcRange.Start.Line,
+						Description:   entity.SecretPlaceholder + " outside a variable; move it into a variable",
+						Unsatisfiable: true,
+					})
+				}
+				continue
+			}
+			attr, ok := block.Body.Attributes["default"]
+			if !ok {
+				continue
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !containsPlaceholder(val) {
+				continue
+			}
+			in := entity.DeployInput{
+				Name:      block.Labels[0],
+				File:      file.Name,
+				Line:      block.DefRange().Start.Line,
+				Sensitive: isTrue(block.Body.Attributes["sensitive"]),
+			}
+			if desc, ok := block.Body.Attributes["description"]; ok {
+				if v, diags := desc.Expr.Value(nil); !diags.HasErrors() && v.Type() == cty.String && v.IsKnown() && !v.IsNull() {
+					in.Description = v.AsString()
+				}
+			}
+			addInput(in)
+		}
+	}
+
+	required := inputs[:0]
+	for _, in := range inputs {
+		if in.Unsatisfiable || !assigned[in.Name] {
+			required = append(required, in)
+		}
+	}
+	return required
+}
+
+// containsPlaceholder — есть ли плейсхолдер в строке или во вложенных значениях.
+func containsPlaceholder(val cty.Value) bool {
+	found := false
+	_ = cty.Walk(val, func(_ cty.Path, v cty.Value) (bool, error) {
+		if v.IsKnown() && !v.IsNull() && v.Type() == cty.String && strings.Contains(v.AsString(), entity.SecretPlaceholder) {
+			found = true
+		}
+		return !found, nil
+	})
+	return found
+}
+
+func referencesVariable(expr hclsyntax.Expression, name string) bool {
+	for _, tr := range hclsyntax.Variables(expr) {
+		if referenceAddr(tr) == "var."+name {
+			return true
+		}
+	}
+	return false
+}
+
+func isTrue(attr *hclsyntax.Attribute) bool {
+	if attr == nil {
+		return false
+	}
+	val, diags := attr.Expr.Value(nil)
+	return !diags.HasErrors() && val.Type() == cty.Bool && val.IsKnown() && !val.IsNull() && val.True()
+}
+
+// blockAddr — адрес блока для сообщений: aws_db_instance.main, provider aws, locals.
+func blockAddr(block *hclsyntax.Block) string {
+	switch {
+	case block.Type == "resource" && len(block.Labels) == 2:
+		return block.Labels[0] + "." + block.Labels[1]
+	case len(block.Labels) > 0:
+		return block.Type + " " + strings.Join(block.Labels, ".")
+	default:
+		return block.Type
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+# Базовые политики для всех проектов. Политики проекта кладутся в projects/<project>/.
+# input — файлы задачи в виде JSON: resources, providers, variables, outputs, modules, locals.
+package terraform.aws
+
+deny contains result if {
+	some r in input.resources
+	r.type == "aws_s3_bucket_acl"
+	r.attributes.acl in {"public-read", "public-read-write"}
+	result := {
+		"msg": sprintf("%s grants public access to the bucket", [r.address]),
+		"rule_id": "POL-AWS-001",
+		"resource": r.address,
+	}
+}
+
+deny contains result if {
+	some r in input.resources
+	r.type == "aws_security_group"
+	some rule in r.attributes.ingress
+	"0.0.0.0/0" in rule.cidr_blocks
+	rule.from_port <= 22
+	rule.to_port >= 22
+	result := {
+		"msg": sprintf("%s opens SSH to the whole internet", [r.address]),
+		"rule_id": "POL-AWS-002",
+		"resource": r.address,
+	}
+}
+
+warn contains result if {
+	some r in input.resources
+	r.type == "aws_db_instance"
+	not r.attributes.storage_encrypted
+	result := {
+		"msg": sprintf("%s does not enable storage_encrypted", [r.address]),
+		"rule_id": "POL-AWS-003",
+		"resource": r.address,
+	}
+}
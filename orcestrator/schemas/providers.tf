@@ -0,0 +1,18 @@
+# Провайдеры, схемы которых встраиваются в образ для офлайн-проверки ресурсов
+# (make provider-schema или стадия schema в Dockerfile).
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "~> 3.6"
+    }
+    tls = {
+      source  = "hashicorp/tls"
+      version = "~> 4.0"
+    }
+  }
+}